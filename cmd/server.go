@@ -7,22 +7,30 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"io"
+	live_stream "live-audio-mixer/internal/live-stream"
 	object_storage "live-audio-mixer/internal/object-storage"
+	stream_handler "live-audio-mixer/internal/stream-handler"
 	pb "live-audio-mixer/proto"
 	records_holder "live-audio-mixer/services/records-holder"
 	"log"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"strconv"
+	"time"
 )
 
 const (
 	DEFAULT_PORT              = 50101
+	DEFAULT_LIVE_PORT         = 50102
 	DEFAULT_DAPR_PORT         = 50001
 	DEFAULT_STORE_NAME        = "object-store"
 	DEFAULT_STORE_B64         = true
 	DEFAULT_DAPR_REQUEST_SIZE = 100
+	DEFAULT_ASSET_CACHE_DIR   = "./cache/"
+	DEFAULT_ASSET_CACHE_MB    = 1024
+	DEFAULT_ASSET_PREFIX      = "assets/"
 )
 
 // server is used to implement helloworld.GreeterServer.
@@ -33,7 +41,7 @@ type server struct {
 
 func (s *server) Start(ctx context.Context, req *pb.RecordRequest) (*pb.RecordReply, error) {
 
-	err := s.service.Record(req.Id)
+	err := s.service.Record(req.Id, req.Output, req.Mix)
 	if err != nil {
 		slog.Info(fmt.Sprintf(`[Server] :: Couldn't start req "%s": %v`, req.Id, err))
 		return nil, err
@@ -53,6 +61,82 @@ func (s *server) Stop(ctx context.Context, req *pb.StopRequest) (*pb.StopReply,
 	return &pb.StopReply{Message: fmt.Sprintf("Recording %s stopped", req.Id)}, nil
 }
 
+func (s *server) Pause(ctx context.Context, req *pb.PauseRequest) (*pb.PauseReply, error) {
+	err := s.service.Pause(req.Id)
+	if err != nil {
+		slog.Info(fmt.Sprintf(`[Server] :: Couldn't pause record "%s": %v`, req.Id, err))
+		return nil, err
+	}
+	slog.Info(fmt.Sprintf(`[Server] :: Record with id "%s" paused`, req.Id))
+	return &pb.PauseReply{Message: fmt.Sprintf("Recording %s paused", req.Id)}, nil
+}
+
+func (s *server) Resume(ctx context.Context, req *pb.ResumeRequest) (*pb.ResumeReply, error) {
+	err := s.service.Resume(req.Id)
+	if err != nil {
+		slog.Info(fmt.Sprintf(`[Server] :: Couldn't resume record "%s": %v`, req.Id, err))
+		return nil, err
+	}
+	slog.Info(fmt.Sprintf(`[Server] :: Record with id "%s" resumed`, req.Id))
+	return &pb.ResumeReply{Message: fmt.Sprintf("Recording %s resumed", req.Id)}, nil
+}
+
+func (s *server) Rerender(ctx context.Context, req *pb.RerenderRequest) (*pb.RerenderReply, error) {
+	key, err := s.service.Rerender(req.Id, req.Output, req.Mix)
+	if err != nil {
+		slog.Info(fmt.Sprintf(`[Server] :: Couldn't render record "%s" again: %v`, req.Id, err))
+		return nil, err
+	}
+	slog.Info(fmt.Sprintf(`[Server] :: Record with id "%s" rendered again to %s`, req.Id, key))
+	return &pb.RerenderReply{Key: key}, nil
+}
+
+func (s *server) Render(ctx context.Context, req *pb.RenderRequest) (*pb.RenderReply, error) {
+	key, err := s.service.Render(req.Id, req.Events, time.Duration(req.DurationMs)*time.Millisecond, req.Output, req.Mix)
+	if err != nil {
+		slog.Info(fmt.Sprintf(`[Server] :: Couldn't render record "%s": %v`, req.Id, err))
+		return nil, err
+	}
+	slog.Info(fmt.Sprintf(`[Server] :: Record with id "%s" rendered to %s`, req.Id, key))
+	return &pb.RenderReply{Key: key}, nil
+}
+
+func (s *server) Watch(req *pb.WatchRequest, stream pb.EventStream_WatchServer) error {
+	statuses, unsubscribe, err := s.service.Watch(req.Id)
+	if err != nil {
+		slog.Info(fmt.Sprintf(`[Server] :: Couldn't watch record "%s": %v`, req.Id, err))
+		return err
+	}
+	defer unsubscribe()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case status, ok := <-statuses:
+			if !ok {
+				// The record is over
+				return nil
+			}
+			if err := stream.Send(status); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *server) ListRecords(ctx context.Context, req *pb.ListRecordsRequest) (*pb.ListRecordsReply, error) {
+	return &pb.ListRecordsReply{Records: s.service.ListRecords()}, nil
+}
+
+func (s *server) GetRecord(ctx context.Context, req *pb.GetRecordRequest) (*pb.RecordInfo, error) {
+	record, err := s.service.GetRecord(req.Id)
+	if err != nil {
+		slog.Info(fmt.Sprintf(`[Server] :: Couldn't get record "%s": %v`, req.Id, err))
+		return nil, err
+	}
+	return record, nil
+}
+
 func (s *server) StreamEvents(stream pb.EventStream_StreamEventsServer) error {
 	for {
 		evt, err := stream.Recv()
@@ -71,6 +155,28 @@ func (s *server) StreamEvents(stream pb.EventStream_StreamEventsServer) error {
 	}
 }
 
+func (s *server) StreamEventsAck(stream pb.EventStream_StreamEventsAckServer) error {
+	for {
+		evt, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		slog.Info(fmt.Sprintf("[Server] :: Received new event : %v", evt))
+		err = s.service.Update(evt)
+		ack := &pb.EventAck{EvtId: evt.EvtId, Code: records_holder.ErrorCode(err), Scheduled: err == nil && evt.ApplyAt != nil}
+		if err != nil {
+			slog.Error(fmt.Sprintf("[Server] :: error handling evt  : %v, %s", evt, err))
+			ack.Error = err.Error()
+		}
+		if err := stream.Send(ack); err != nil {
+			return err
+		}
+	}
+}
+
 func main() {
 	pEnv := parseEnv()
 	slog.Info("[Main] :: Dapr port is " + strconv.Itoa(pEnv.daprGrpcPort))
@@ -85,8 +191,42 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
+	// Assets are fetched and transcoded every time they are played when the cache is disabled or unusable
+	var assets *stream_handler.AssetCache
+	if pEnv.assetCacheMB > 0 {
+		assets, err = stream_handler.NewAssetCache(pEnv.assetCacheDir, int64(pEnv.assetCacheMB)*1024*1024)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("[Main] :: Assets won't be cached, %s can't be used : %v", pEnv.assetCacheDir, err))
+			assets = nil
+		}
+	}
+	// Local files and objects can only be played from the configured directory and prefix
+	sources := stream_handler.DefaultSources()
+	if pEnv.assetDir != "" {
+		files, err := stream_handler.NewFileSource(pEnv.assetDir)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("[Main] :: Local assets can't be played, %s can't be used : %v", pEnv.assetDir, err))
+		} else {
+			sources["file"] = files
+		}
+	}
+	if pEnv.assetPrefix != "" {
+		sources[stream_handler.ObjectScheme] = stream_handler.NewObjectSource(store, pEnv.assetPrefix)
+	}
+	service := records_holder.NewRecordsHolder(store, assets, sources)
+
+	// Start the HTTP server for live streams
+	mux := http.NewServeMux()
+	mux.Handle(live_stream.LivePath, live_stream.NewHandler(service.GetLiveStream))
+	go func() {
+		slog.Info(fmt.Sprintf("[Main] :: Live streams available at :%d%s<record id>", pEnv.livePort, live_stream.LivePath))
+		if err := http.ListenAndServe(fmt.Sprintf(":%d", pEnv.livePort), mux); err != nil {
+			log.Fatalf("failed to serve live streams: %v", err)
+		}
+	}()
+
 	s := grpc.NewServer()
-	pb.RegisterEventStreamServer(s, &server{service: records_holder.NewRecordsHolder(store)})
+	pb.RegisterEventStreamServer(s, &server{service: service})
 	log.Printf("server listening at %v", lis.Addr())
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
@@ -97,20 +237,33 @@ type env struct {
 	// Port to connect to Dapr sidecar
 	daprGrpcPort int
 	// Port the app is listening on
-	serverPort           int
+	serverPort int
+	// Port live streams are served on
+	livePort             int
 	daprMaxRequestSizeMB int
 	// Dapr components ids
 	daprCpnObject    string
 	daprCpnObjectB64 bool
+	// Directory and maximum size of the cache of transcoded assets, 0 disabling it
+	assetCacheDir string
+	assetCacheMB  int
+	// Directory local assets are played from, and prefix of the keys of the assets in the object store.
+	// Empty values disable the file:// and object:// schemes
+	assetDir    string
+	assetPrefix string
 }
 
 func parseEnv() *env {
 	pEnv := env{
 		serverPort:           DEFAULT_PORT,
+		livePort:             DEFAULT_LIVE_PORT,
 		daprMaxRequestSizeMB: DEFAULT_DAPR_REQUEST_SIZE,
 		daprGrpcPort:         DEFAULT_DAPR_PORT,
 		daprCpnObject:        DEFAULT_STORE_NAME,
 		daprCpnObjectB64:     DEFAULT_STORE_B64,
+		assetCacheDir:        DEFAULT_ASSET_CACHE_DIR,
+		assetCacheMB:         DEFAULT_ASSET_CACHE_MB,
+		assetPrefix:          DEFAULT_ASSET_PREFIX,
 	}
 
 	if envPort, err := strconv.ParseInt(os.Getenv("DAPR_GRPC_PORT"), 10, 32); err == nil && envPort != 0 {
@@ -119,6 +272,9 @@ func parseEnv() *env {
 	if envPort, err := strconv.ParseInt(os.Getenv("SERVER_PORT"), 10, 32); err == nil && envPort != 0 {
 		pEnv.serverPort = int(envPort)
 	}
+	if envPort, err := strconv.ParseInt(os.Getenv("LIVE_PORT"), 10, 32); err == nil && envPort != 0 {
+		pEnv.livePort = int(envPort)
+	}
 	if envPort, err := strconv.ParseInt(os.Getenv("DAPR_MAX_REQUEST_SIZE_MB"), 10, 32); err == nil {
 		pEnv.daprMaxRequestSizeMB = int(envPort)
 	}
@@ -128,6 +284,16 @@ func parseEnv() *env {
 	if b64, err := strconv.ParseBool(os.Getenv("OBJECT_STORE_B64")); err == nil {
 		pEnv.daprCpnObjectB64 = b64
 	}
+	if dir, isDefined := os.LookupEnv("ASSET_CACHE_DIR"); isDefined && dir != "" {
+		pEnv.assetCacheDir = dir
+	}
+	if size, err := strconv.ParseInt(os.Getenv("ASSET_CACHE_SIZE_MB"), 10, 32); err == nil {
+		pEnv.assetCacheMB = int(size)
+	}
+	pEnv.assetDir = os.Getenv("ASSET_DIR")
+	if prefix, isDefined := os.LookupEnv("OBJECT_STORE_ASSET_PREFIX"); isDefined {
+		pEnv.assetPrefix = prefix
+	}
 	return &pEnv
 }
 
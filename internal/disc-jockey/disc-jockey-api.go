@@ -1,28 +1,142 @@
 package disc_jockey
 
 import (
+	"errors"
 	"github.com/faiface/beep"
 	"github.com/faiface/beep/effects"
 	"sync"
+	"time"
+)
+
+// Maximum duration of the mix rendered at once while holding the lock
+const maxRenderBlock = 20 * time.Millisecond
+
+// DefaultDucking lowers the music by 12dB while effects or voices are heard
+var DefaultDucking = DuckingOpt{
+	Enabled:       true,
+	ThresholdDb:   -40,
+	AttenuationDb: -12,
+	Attack:        50 * time.Millisecond,
+	Release:       500 * time.Millisecond,
+}
+
+var (
+	// ErrTrackNotFound is returned when targeting a track that is not in the mixtable
+	ErrTrackNotFound = errors.New("track not found")
+	// ErrTrackExists is returned when adding a track with the id of a track already in the mixtable
+	ErrTrackExists = errors.New("track already exists")
 )
 
 type Track struct {
 	// The original stream
 	Origin beep.StreamSeekCloser
+	// Starts the original stream over when looping, nil for a track without stream
+	looper *looper
 	// The actual played stream with all required controls
 	Decorated *effects.Volume
+	// Gain envelope applied on top of the decorated stream, used for fades
+	fader *envelope
+	// Decibels envelope used to smooth volume changes
+	volumeRamp *envelope
+	// Set when the track has been removed from the track list
+	removed bool
+	// Sample rate of the original stream
+	sampleRate beep.SampleRate
+	role       Role
+}
+
+// Role tells how a track takes part in ducking
+type Role int
+
+const (
+	// RoleNone tracks are never ducked, and don't trigger ducking
+	RoleNone Role = iota
+	// RoleMusic tracks are ducked while an effect or a voice is heard
+	RoleMusic
+	// RoleEffect tracks trigger ducking
+	RoleEffect
+	// RoleVoice tracks trigger ducking
+	RoleVoice
+)
+
+// DuckingOpt configures how music tracks are lowered while effects or voices are heard
+type DuckingOpt struct {
+	Enabled bool
+	// Level of the effects and voices above which the music is ducked, in dBFS
+	ThresholdDb float64
+	// Gain applied to the music while ducked, in decibels
+	AttenuationDb float64
+	// Time it takes for the music to be ducked once an effect or a voice is heard
+	Attack time.Duration
+	// Time it takes for the music to come back once effects and voices are over
+	Release time.Duration
+}
+
+// TrackInfo is a snapshot of the state of a track
+type TrackInfo struct {
+	Id     string
+	Paused bool
+	// Volume in decibels. If a ramp is in progress, this is the level reached so far
+	VolumeDb float64
+	// Playback position in the original stream
+	Position time.Duration
+	// Length of the original stream, 0 when unknown
+	Length time.Duration
+	Role   Role
+	// Number of times the track started over so far
+	Loops int
 }
 
 // DiscJockey is a mixer that can play multiple tracks at the same time
 type DiscJockey struct {
+	// Sample rate of the mix
+	sampleRate beep.SampleRate
+	// Tracks are mixed by role. Music tracks are ducked by the sidechain, made of the effect and voice tracks
 	mixer     beep.Mixer
+	music     beep.Mixer
+	sidechain beep.Mixer
+	ducker    *ducker
+
+	// Buffers the music and the sidechain are rendered to before being mixed
+	musicBuf, sidechainBuf [][2]float64
+
 	lock      sync.Mutex
 	trackList map[string]*Track
+	// Tracks being faded out, with their id. They are detached from their id, which can be used by a new track
+	fading map[*Track]string
 }
 
 type AddTrackOpt struct {
 	// The initial volume of the track in decibels
 	InitVolumeDb float64
-	// The callback to call when the track is finished
+	// How the track takes part in ducking
+	Role Role
+	// Duration over which the track volume ramps up from silence when it starts
+	FadeIn time.Duration
+	// Position in the asset the stream starts at. Streams opened from an offset which count their position
+	// from 0, such as live transcodes, are reported from there
+	Offset time.Duration
+	// The callback to call when the track is finished. It is not called if the track is removed beforehand
 	OnEnd func(string)
+	// How the track starts over once finished
+	Loop LoopOpt
+	// The callback to call each time the track starts over
+	OnLoop func(string)
+}
+
+// LoopOpt configures how a track starts over. Looping is gapless: the start of the loop region
+// follows its end right away, to the sample
+type LoopOpt struct {
+	Enabled bool
+	// Number of times the track starts over, 0 meaning forever. The track then plays on until its end
+	Count int
+	// Region of the original stream played over. The track plays from wherever it starts up to End, then
+	// starts over from Start. An End of 0 is the end of the stream
+	Start, End time.Duration
+	// Opens a new stream of the track, from its beginning, for streams which can't be sought back. Each iteration
+	// then plays a new stream, opened in the background ahead of time
+	Reopen func() (beep.StreamSeekCloser, error)
+	// When set, the track waits for the new stream of its next iteration instead of being silent until it is ready.
+	// Used when rendering offline, where the mix must not depend on how long streams take to open
+	Wait bool
 }
@@ -6,52 +6,82 @@ import (
 	"github.com/faiface/beep/effects"
 	"log/slog"
 	"sync"
+	"time"
 )
 
 // A collection of utilities taking a collection of streamseeker and handling control
 // related operations like looping, seeking, pausing...
 
-func NewDiscJockey() *DiscJockey {
+// NewDiscJockey creates a new mixtable. Every track is resampled to the provided sample rate
+func NewDiscJockey(sampleRate beep.SampleRate) *DiscJockey {
+	block := sampleRate.N(maxRenderBlock)
 	return &DiscJockey{
-		mixer:     beep.Mixer{},
-		lock:      sync.Mutex{},
-		trackList: map[string]*Track{},
+		sampleRate:   sampleRate,
+		mixer:        beep.Mixer{},
+		music:        beep.Mixer{},
+		sidechain:    beep.Mixer{},
+		musicBuf:     make([][2]float64, block),
+		sidechainBuf: make([][2]float64, block),
+		ducker:       newDucker(DefaultDucking, sampleRate),
+		lock:         sync.Mutex{},
+		trackList:    map[string]*Track{},
+		fading:       map[*Track]string{},
 	}
 }
 
 // Add a new track to the mixtable, the onEnd callback is called when the track is finished
 // A new track is played automatically
 func (dj *DiscJockey) Add(id string, s beep.StreamSeekCloser, format beep.Format, opt AddTrackOpt) error {
+	// The lock is held from the check to the insertion, so that two tracks can't be added with the same id
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
 
 	// abort if the track already exists
 	if _, err := dj.getTrack(id); err == nil {
-		return fmt.Errorf("track with id %s already exists", id)
+		return fmt.Errorf(`%w: "%s"`, ErrTrackExists, id)
 	}
 
-	var target beep.Streamer = s
+	sampleRate := format.SampleRate
 	if format.SampleRate == beep.SampleRate(0) {
-		slog.Info(fmt.Sprintf("[Disc Jockey] :: Track %s has a sample rate of 0. Assuming 48000 and hoping for the best", id))
-	} else if format.SampleRate != beep.SampleRate(48000) {
-		slog.Info(fmt.Sprintf("[Disc Jockey] :: Resampling track %s from %d to 48000", id, format.SampleRate))
-		target = beep.Resample(3, format.SampleRate, beep.SampleRate(48000), s)
+		slog.Info(fmt.Sprintf("[Disc Jockey] :: Track %s has a sample rate of 0. Assuming %d and hoping for the best", id, dj.sampleRate))
+		sampleRate = dj.sampleRate
+	}
+	// Looping happens on the original stream, so that the loop region is not affected by resampling
+	var origin *looper
+	if s != nil {
+		origin = newLooper(s, opt.Loop, sampleRate.N(opt.Offset), sampleRate, func() {
+			if opt.OnLoop != nil {
+				go opt.OnLoop(id)
+			}
+		})
+		s = origin
+	}
+	var target beep.Streamer = s
+	if format.SampleRate != 0 && format.SampleRate != dj.sampleRate {
+		slog.Info(fmt.Sprintf("[Disc Jockey] :: Resampling track %s from %d to %d", id, format.SampleRate, dj.sampleRate))
+		target = beep.Resample(3, format.SampleRate, dj.sampleRate, s)
 	}
 
 	// Every time a song stops playing, it is removed from the track list
+	var track *Track
 	afterPlayCb := beep.Callback(func() {
 		go func(id string) {
-			err := dj.Remove(id)
-			if err != nil {
-				slog.Debug(fmt.Sprintf("[Disc Jockey] :: Error while removing track %s  in callback. Song has been stopped beforehand : %v", id, err))
+			// The track may have been stopped, and another instance added with the same id, before the lock is held
+			if !dj.removeInstance(id, track) {
+				slog.Debug(fmt.Sprintf("[Disc Jockey] :: Track %s ended after being stopped beforehand", id))
+				return
 			}
-
 			if opt.OnEnd != nil {
 				opt.OnEnd(id)
 			}
 		}(id)
 	})
 
-	track := &Track{
-		Origin: s,
+	track = &Track{
+		Origin:     s,
+		looper:     origin,
+		sampleRate: sampleRate,
+		role:       opt.Role,
 		Decorated: &effects.Volume{
 			Streamer: &beep.Ctrl{Streamer: beep.Seq(target, afterPlayCb), Paused: false},
 			// Logarithmic base for volume control
@@ -64,13 +94,33 @@ func (dj *DiscJockey) Add(id string, s beep.StreamSeekCloser, format beep.Format
 			Silent: false,
 		},
 	}
-	dj.lock.Lock()
-	defer dj.lock.Unlock()
+	track.volumeRamp = newDbEnvelope(track.Decorated)
+	// Without any fade-in, the envelope is a no-op with a unity gain
+	track.fader = newEnvelope(track.volumeRamp, 0, 1, dj.sampleRate.N(opt.FadeIn))
 	dj.trackList[id] = track
-	dj.mixer.Add(track.Decorated)
+	// Once removed, a track instance is dropped from the mixer without being streamed again.
+	// This prevents its end callback from firing and targeting another instance re-added with the same id
+	dj.mixerOf(opt.Role).Add(beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		if track.removed {
+			return 0, false
+		}
+		return track.fader.Stream(samples)
+	}))
 	return nil
 }
 
+// mixerOf returns the mixer the tracks of a role are mixed in
+func (dj *DiscJockey) mixerOf(role Role) *beep.Mixer {
+	switch role {
+	case RoleMusic:
+		return &dj.music
+	case RoleEffect, RoleVoice:
+		return &dj.sidechain
+	default:
+		return &dj.mixer
+	}
+}
+
 func (dj *DiscJockey) Remove(id string) error {
 	dj.lock.Lock()
 	defer dj.lock.Unlock()
@@ -78,14 +128,78 @@ func (dj *DiscJockey) Remove(id string) error {
 	if err != nil {
 		return err
 	}
-	err = track.Origin.Close()
+	dj.remove(id, track)
+	return nil
+}
+
+// removeInstance removes a track instance, returning false if it has already been removed or is being faded out.
+// Another instance added with the same id since then is left untouched
+func (dj *DiscJockey) removeInstance(id string, track *Track) bool {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	if _, ok := dj.fading[track]; ok {
+		// The track ended before the end of its fade out
+		dj.dropFaded(track)
+		return false
+	}
+	if dj.trackList[id] != track {
+		return false
+	}
+	dj.remove(id, track)
+	return true
+}
+
+// remove closes a track and removes it from the track list. The lock must be held
+func (dj *DiscJockey) remove(id string, track *Track) {
+	dj.close(id, track)
+	delete(dj.trackList, id)
+}
+
+// dropFaded closes a track once faded out, if it is not closed yet. The lock must be held
+func (dj *DiscJockey) dropFaded(track *Track) {
+	id, ok := dj.fading[track]
+	if !ok {
+		return
+	}
+	dj.close(id, track)
+	delete(dj.fading, track)
+}
+
+// close closes the stream of a track, which is then dropped from the mixer. The lock must be held
+func (dj *DiscJockey) close(id string, track *Track) {
+	err := track.Origin.Close()
 	if err != nil {
 		// This is not a fatal error, we can still remove the track from the list
 		// this can happen if during the time the callback was executing, the source
 		// autoclosed
 		slog.Warn(fmt.Sprintf("while closing track %s: %s", id, err.Error()))
 	}
+	track.removed = true
+}
+
+// FadeOut progressively lowers the volume of a track to silence over the given duration, then removes it.
+// The track is detached from its id right away, so that a new track can be added with the same id during the fade.
+// A zero duration is equivalent to Remove
+func (dj *DiscJockey) FadeOut(id string, duration time.Duration) error {
+	if duration <= 0 {
+		return dj.Remove(id)
+	}
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	track, err := dj.getTrack(id)
+	if err != nil {
+		return err
+	}
 	delete(dj.trackList, id)
+	dj.fading[track] = id
+	// The end of the ramp is reached while streaming, with the lock held
+	track.fader.rampTo(0, dj.sampleRate.N(duration), func() {
+		go func() {
+			dj.lock.Lock()
+			defer dj.lock.Unlock()
+			dj.dropFaded(track)
+		}()
+	})
 	return nil
 }
 
@@ -95,8 +209,14 @@ func (dj *DiscJockey) CloseAll() {
 	defer dj.lock.Unlock()
 	for _, track := range dj.trackList {
 		track.Origin.Close()
+		track.removed = true
+	}
+	for track := range dj.fading {
+		track.Origin.Close()
+		track.removed = true
 	}
 	dj.trackList = map[string]*Track{}
+	dj.fading = map[*Track]string{}
 }
 
 // SetPaused a single track
@@ -137,18 +257,174 @@ func (dj *DiscJockey) ChangeVolume(id string, volumeDeltaDb float64) error {
 	return nil
 }
 
+// RampVolume progressively moves the volume of a track from its current level to targetDb over the given duration.
+// Any ramp already in progress is interrupted, and the new one starts from the level reached so far
+func (dj *DiscJockey) RampVolume(id string, targetDb float64, duration time.Duration) error {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	track, err := dj.getTrack(id)
+	if err != nil {
+		return err
+	}
+	// The volume filter is immediately set to the target level. The ramp then
+	// compensates for the difference between the target and the current level, reaching 0dB at the end
+	currentDb := track.Decorated.Volume*20 + track.volumeRamp.value()
+	track.Decorated.Volume = targetDb / 20
+	// The track must stay audible during the ramp, even if it ends up silent
+	track.Decorated.Silent = false
+	track.volumeRamp.ramp(currentDb-targetDb, 0, dj.sampleRate.N(duration), func() {
+		// Called while streaming, with the lock already held
+		track.Decorated.Silent = targetDb <= -60
+	})
+	return nil
+}
+
+// GetVolume returns the volume of a track in decibels. If a ramp is in progress, this is the level
+// the ramp is going to
+func (dj *DiscJockey) GetVolume(id string) (float64, error) {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	track, err := dj.getTrack(id)
+	if err != nil {
+		return 0, err
+	}
+	return track.Decorated.Volume * 20, nil
+}
+
+// SetLooping enables or disables the looping of a track. Once disabled, the track plays on until its end
+func (dj *DiscJockey) SetLooping(id string, enabled bool) error {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	track, err := dj.getTrack(id)
+	if err != nil {
+		return err
+	}
+	if track.looper != nil {
+		track.looper.setLooping(enabled)
+	}
+	return nil
+}
+
+// Seek moves a track to the given position of its stream, keeping its volume, pause and loop state.
+// It fails for tracks whose stream can't be sought, such as streams being transcoded
+func (dj *DiscJockey) Seek(id string, position time.Duration) error {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	track, err := dj.getTrack(id)
+	if err != nil {
+		return err
+	}
+	if track.looper == nil {
+		return fmt.Errorf(`track "%s" has no stream to seek`, id)
+	}
+	return track.looper.Seek(track.sampleRate.N(position))
+}
+
+// Replace plays a track on from a new stream of its asset, opened at the given position, for tracks whose stream
+// can't be sought. The track keeps its volume, fades, pause and loop state, and the replaced stream is closed
+func (dj *DiscJockey) Replace(id string, s beep.StreamSeekCloser, position time.Duration) error {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	track, err := dj.getTrack(id)
+	if err != nil {
+		return err
+	}
+	if track.looper == nil {
+		return fmt.Errorf(`track "%s" has no stream to replace`, id)
+	}
+	track.looper.replace(s, track.sampleRate.N(position))
+	return nil
+}
+
+// SetDucking changes how music tracks are ducked by effect and voice tracks
+func (dj *DiscJockey) SetDucking(opt DuckingOpt) {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	dj.ducker.set(opt, dj.sampleRate)
+}
+
+// Ducking returns the current ducking settings
+func (dj *DiscJockey) Ducking() DuckingOpt {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	return dj.ducker.opt
+}
+
+// Tracks returns a snapshot of every track in the mixtable, by id. Tracks being faded out are listed
+// as well, unless a new track uses their id
+func (dj *DiscJockey) Tracks() map[string]TrackInfo {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	tracks := make(map[string]TrackInfo, len(dj.trackList)+len(dj.fading))
+	for track, id := range dj.fading {
+		if _, ok := dj.trackList[id]; !ok {
+			tracks[id] = trackInfo(id, track)
+		}
+	}
+	for id, track := range dj.trackList {
+		tracks[id] = trackInfo(id, track)
+	}
+	return tracks
+}
+
+// trackInfo returns a snapshot of the state of a track. The lock must be held
+func trackInfo(id string, track *Track) TrackInfo {
+	info := TrackInfo{
+		Id:       id,
+		Paused:   track.Decorated.Streamer.(*beep.Ctrl).Paused,
+		VolumeDb: track.Decorated.Volume*20 + track.volumeRamp.value(),
+		Role:     track.role,
+	}
+	if track.looper != nil {
+		info.Position = track.sampleRate.D(track.looper.Position())
+		info.Length = track.sampleRate.D(track.looper.Len())
+		info.Loops = track.looper.loops
+	}
+	return info
+}
+
+// Has tells whether a track with the given id is in the mixtable. Tracks fading out don't hold their id anymore
+func (dj *DiscJockey) Has(id string) bool {
+	dj.lock.Lock()
+	defer dj.lock.Unlock()
+	_, ok := dj.trackList[id]
+	return ok
+}
+
 func (dj *DiscJockey) getTrack(id string) (*Track, error) {
 	track, ok := dj.trackList[id]
 	if !ok {
-		return nil, fmt.Errorf(`track with id "%s" not found`, id)
+		return nil, fmt.Errorf(`%w: "%s"`, ErrTrackNotFound, id)
 	}
 	return track, nil
 }
 
+// Stream renders the mix. Large requests are rendered block by block, and the lock is released in between,
+// so that commands never wait for more than a block to be rendered
 func (dj *DiscJockey) Stream(samples [][2]float64) (n int, ok bool) {
-	dj.lock.Lock()
-	defer dj.lock.Unlock()
-	return dj.mixer.Stream(samples)
+	block := len(dj.musicBuf)
+	for n < len(samples) {
+		end := min(n+block, len(samples))
+		dj.lock.Lock()
+		dj.render(samples[n:end])
+		dj.lock.Unlock()
+		n = end
+	}
+	return n, true
+}
+
+// render mixes every track into samples, ducking the music by the sidechain.
+// Mixers always fill the whole buffer, with silence when they have no track
+func (dj *DiscJockey) render(samples [][2]float64) {
+	music, sidechain := dj.musicBuf[:len(samples)], dj.sidechainBuf[:len(samples)]
+	dj.mixer.Stream(samples)
+	dj.music.Stream(music)
+	dj.sidechain.Stream(sidechain)
+	dj.ducker.process(music, sidechain)
+	for i := range samples {
+		samples[i][0] += music[i][0] + sidechain[i][0]
+		samples[i][1] += music[i][1] + sidechain[i][1]
+	}
 }
 func (dj *DiscJockey) Err() error {
 	return dj.mixer.Err()
@@ -1,24 +1,54 @@
 package disc_jockey
 
 import (
+	"fmt"
 	"github.com/faiface/beep"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	test_utils "live-audio-mixer/test-utils"
+	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 func TestDiscJockey_AddNoDup(t *testing.T) {
-	dj := NewDiscJockey()
+	dj := NewDiscJockey(48000)
 	err := dj.Add("test", nil, beep.Format{}, AddTrackOpt{})
 	assert.NoError(t, err)
 	err = dj.Add("test", nil, beep.Format{}, AddTrackOpt{})
 	assert.Error(t, err)
 }
 
+// Tracks added at the same time with the same id are added once, while others end concurrently
+func TestDiscJockey_AddConcurrently(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	var (
+		added atomic.Int32
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if dj.Add("test", nil, beep.Format{}, AddTrackOpt{}) == nil {
+				added.Add(1)
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("ending-%d", i)
+			assert.NoError(t, dj.Add(id, test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{}))
+			assert.NoError(t, dj.Remove(id))
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), added.Load())
+}
+
 func TestDiscJockey_EndCallback(t *testing.T) {
-	dj := NewDiscJockey()
+	dj := NewDiscJockey(48000)
 	// And write them to a file
 	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
 	quack := test_utils.OpenMp3Resource(t, test_utils.Mp3_Quack)
@@ -39,7 +69,7 @@ func TestDiscJockey_EndCallback(t *testing.T) {
 
 // This can happen when the format couldn't be determined
 func TestDiscJockey_SampleRateZero(t *testing.T) {
-	dj := NewDiscJockey()
+	dj := NewDiscJockey(48000)
 	// And write them to a file
 	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
 	quack := test_utils.OpenMp3Resource(t, test_utils.Mp3_Quack)
@@ -58,7 +88,7 @@ func TestDiscJockey_SampleRateZero(t *testing.T) {
 }
 
 func TestDiscJockey_Remove(t *testing.T) {
-	dj := NewDiscJockey()
+	dj := NewDiscJockey(48000)
 	mockStream := MockStreamer{}
 	mockStream.On("Close").Return(nil)
 	err := dj.Add("test", &mockStream, beep.Format{}, AddTrackOpt{})
@@ -71,8 +101,21 @@ func TestDiscJockey_Remove(t *testing.T) {
 	assert.Error(t, err)
 }
 
+// The end of a stopped track doesn't remove another instance added with the same id
+func TestDiscJockey_RemoveInstance(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	assert.NoError(t, dj.Add("test", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{}))
+	stopped := dj.trackList["test"]
+	assert.NoError(t, dj.Remove("test"))
+	assert.NoError(t, dj.Add("test", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{}))
+	assert.False(t, dj.removeInstance("test", stopped))
+	assert.Contains(t, dj.Tracks(), "test")
+	assert.True(t, dj.removeInstance("test", dj.trackList["test"]))
+	assert.Empty(t, dj.Tracks())
+}
+
 func TestDiscJockey_SetPaused(t *testing.T) {
-	dj := NewDiscJockey()
+	dj := NewDiscJockey(48000)
 	mockStream := MockStreamer{}
 	mockStream.On("Close").Return(nil)
 	err := dj.Add("test", &mockStream, beep.Format{}, AddTrackOpt{})
@@ -92,7 +135,7 @@ func TestDiscJockey_SetPaused(t *testing.T) {
 }
 
 func TestDiscJockey_ChangeVolume(t *testing.T) {
-	dj := NewDiscJockey()
+	dj := NewDiscJockey(48000)
 	mockStream := MockStreamer{}
 	err := dj.Add("test", &mockStream, beep.Format{}, AddTrackOpt{})
 	assert.NoError(t, err)
@@ -110,6 +153,333 @@ func TestDiscJockey_ChangeVolume(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDiscJockey_FadeIn(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("test", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{FadeIn: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	// 10ms at 48kHz is 480 samples
+	samples := test_utils.GetSamples(t, dj, 960)
+	assert.InDelta(t, 0, samples[0][0], 0.01)
+	assert.InDelta(t, 0.5, samples[240][0], 0.01)
+	assert.InDelta(t, 1, samples[480][0], 0.01)
+	assert.InDelta(t, 1, samples[959][1], 0.01)
+}
+
+func TestDiscJockey_FadeOut(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("test", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{})
+	assert.NoError(t, err)
+	err = dj.FadeOut("test", 10*time.Millisecond)
+	assert.NoError(t, err)
+	samples := test_utils.GetSamples(t, dj, 960)
+	assert.InDelta(t, 1, samples[0][0], 0.01)
+	assert.InDelta(t, 0.5, samples[240][0], 0.01)
+	assert.InDelta(t, 0, samples[480][0], 0.01)
+	assert.InDelta(t, 0, samples[959][1], 0.01)
+	// The track is removed once the fade out is over
+	assert.Eventually(t, func() bool {
+		dj.lock.Lock()
+		defer dj.lock.Unlock()
+		_, err := dj.getTrack("test")
+		return err != nil && len(dj.fading) == 0
+	}, time.Second, 10*time.Millisecond)
+	err = dj.FadeOut("test", 10*time.Millisecond)
+	assert.Error(t, err)
+}
+
+// A track can be added again with the id of a track being faded out, both being heard meanwhile
+func TestDiscJockey_FadeOutRestart(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	assert.NoError(t, dj.Add("amb", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{}))
+	assert.NoError(t, dj.FadeOut("amb", 10*time.Millisecond))
+	// Until then, the track being faded out is listed
+	assert.Contains(t, dj.Tracks(), "amb")
+	assert.NoError(t, dj.Add("amb", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{InitVolumeDb: -6}))
+	assert.InDelta(t, -6, dj.Tracks()["amb"].VolumeDb, 0.01)
+	samples := test_utils.GetSamples(t, dj, 960)
+	assert.InDelta(t, 1.5, samples[0][0], 0.01)
+	assert.InDelta(t, 0.5, samples[959][0], 0.01)
+	assert.Eventually(t, func() bool {
+		dj.lock.Lock()
+		defer dj.lock.Unlock()
+		return len(dj.fading) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.Contains(t, dj.Tracks(), "amb")
+	// Only the new track is stopped
+	assert.NoError(t, dj.Remove("amb"))
+	assert.Empty(t, dj.Tracks())
+}
+
+func TestDiscJockey_RampVolume(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("test", test_utils.NewConstant(1), beep.Format{}, AddTrackOpt{})
+	assert.NoError(t, err)
+	// Ramping from 0dB to -20dB over 10ms (480 samples). Halfway, we should be at -10dB
+	err = dj.RampVolume("test", -20, 10*time.Millisecond)
+	assert.NoError(t, err)
+	volume, err := dj.GetVolume("test")
+	assert.NoError(t, err)
+	assert.Equal(t, -20.0, volume)
+	samples := test_utils.GetSamples(t, dj, 960)
+	assert.InDelta(t, 1, samples[0][0], 0.01)
+	assert.InDelta(t, math.Pow(10, -10.0/20), samples[240][0], 0.01)
+	assert.InDelta(t, 0.1, samples[480][0], 0.01)
+	assert.InDelta(t, 0.1, samples[959][1], 0.01)
+
+	// Ramping down to silence only mutes the track at the end of the ramp
+	err = dj.RampVolume("test", -60, 10*time.Millisecond)
+	assert.NoError(t, err)
+	track, err := dj.getTrack("test")
+	assert.NoError(t, err)
+	assert.False(t, track.Decorated.Silent)
+	samples = test_utils.GetSamples(t, dj, 960)
+	assert.InDelta(t, 0.1, samples[0][0], 0.01)
+	assert.Equal(t, 0.0, samples[959][0])
+	assert.True(t, track.Decorated.Silent)
+
+	err = dj.RampVolume("unknown", 0, 0)
+	assert.Error(t, err)
+}
+
+// Rendering a long chunk of mix does not hold back commands until it is over
+func TestDiscJockey_StreamDoesNotHoldLock(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("slow", &slowStreamer{test_utils.NewConstant(1)}, beep.Format{}, AddTrackOpt{})
+	assert.NoError(t, err)
+	rendered := make(chan struct{})
+	go func() {
+		// Takes about 500ms to render
+		test_utils.GetSamples(t, dj, 48000)
+		close(rendered)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	start := time.Now()
+	err = dj.ChangeVolume("slow", -6)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), 100*time.Millisecond)
+	select {
+	case <-rendered:
+		assert.Fail(t, "the command should have been applied while rendering")
+	default:
+	}
+	<-rendered
+}
+
+func TestDiscJockey_Tracks(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("finite", &test_utils.Constant{Sample: [2]float64{1, 1}, Length: 24000}, beep.Format{SampleRate: 24000}, AddTrackOpt{InitVolumeDb: -6})
+	assert.NoError(t, err)
+	err = dj.Add("endless", test_utils.NewConstant(1), beep.Format{SampleRate: 48000}, AddTrackOpt{})
+	assert.NoError(t, err)
+	assert.NoError(t, dj.SetPaused("endless", true))
+	test_utils.GetSamples(t, dj, 48000/2)
+
+	tracks := dj.Tracks()
+	assert.Len(t, tracks, 2)
+	finite := tracks["finite"]
+	assert.False(t, finite.Paused)
+	assert.InDelta(t, -6, finite.VolumeDb, 1e-9)
+	assert.Equal(t, time.Second, finite.Length)
+	// The resampler reads ahead of the mix
+	assert.InDelta(t, 500*time.Millisecond, finite.Position, float64(25*time.Millisecond))
+	endless := tracks["endless"]
+	assert.True(t, endless.Paused)
+	assert.Equal(t, time.Duration(0), endless.Length)
+
+	// The volume reported during a ramp is the level reached so far
+	assert.NoError(t, dj.RampVolume("finite", -26, time.Second))
+	test_utils.GetSamples(t, dj, 48000/2)
+	assert.InDelta(t, -16, dj.Tracks()["finite"].VolumeDb, 0.5)
+}
+
+func TestDiscJockey_Ducking(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	dj.SetDucking(DuckingOpt{Enabled: true, ThresholdDb: -40, AttenuationDb: -12, Attack: 10 * time.Millisecond, Release: 100 * time.Millisecond})
+	assert.NoError(t, dj.Add("music", test_utils.NewConstant(1), beep.Format{SampleRate: 48000}, AddTrackOpt{Role: RoleMusic}))
+	assert.NoError(t, dj.Add("ambience", test_utils.NewConstant(1), beep.Format{SampleRate: 48000}, AddTrackOpt{InitVolumeDb: -20}))
+	assert.InDelta(t, 1.1, test_utils.GetSamples(t, dj, 4800)[4799][0], 1e-9)
+
+	// The music dips while the effect plays, the other tracks are left untouched
+	assert.NoError(t, dj.Add("effect", &test_utils.Constant{Sample: [2]float64{1, 1}, Length: 24000}, beep.Format{SampleRate: 48000}, AddTrackOpt{Role: RoleEffect, InitVolumeDb: -20}))
+	assert.Equal(t, RoleEffect, dj.Tracks()["effect"].Role)
+	samples := test_utils.GetSamples(t, dj, 12000)
+	assert.Greater(t, samples[0][0], 1.1)
+	assert.InDelta(t, math.Pow(10, -12.0/20)+0.2, samples[len(samples)-1][0], 1e-3)
+
+	// And comes back once the effect is over
+	test_utils.GetSamples(t, dj, 12000)
+	samples = test_utils.GetSamples(t, dj, 48000)
+	assert.Less(t, samples[0][0], 1.)
+	assert.InDelta(t, 1.1, samples[len(samples)-1][0], 1e-3)
+
+	// Voices duck the music as well, unless ducking is disabled
+	assert.NoError(t, dj.Add("voice", test_utils.NewConstant(1), beep.Format{SampleRate: 48000}, AddTrackOpt{Role: RoleVoice, InitVolumeDb: -20}))
+	samples = test_utils.GetSamples(t, dj, 12000)
+	assert.InDelta(t, math.Pow(10, -12.0/20)+0.2, samples[len(samples)-1][0], 1e-3)
+	dj.SetDucking(DuckingOpt{})
+	assert.False(t, dj.Ducking().Enabled)
+	samples = test_utils.GetSamples(t, dj, 48000)
+	assert.InDelta(t, 1.2, samples[len(samples)-1][0], 1e-3)
+}
+
+func TestDiscJockey_Loop(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	loops, ended := make(chan string, 10), make(chan string, 1)
+	// 1ms is 48 samples. The region is played over twice, then the track plays on until its end
+	err := dj.Add("seek", &test_utils.Ramp{Length: 200}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Loop:   LoopOpt{Enabled: true, Count: 2, Start: time.Millisecond, End: 2 * time.Millisecond},
+		OnLoop: func(id string) { loops <- id },
+		OnEnd:  func(id string) { ended <- id },
+	})
+	assert.NoError(t, err)
+	samples := test_utils.GetSamples(t, dj, 400)
+	var expected []float64
+	for _, region := range [][2]int{{0, 96}, {48, 96}, {48, 96}, {96, 200}} {
+		for i := region[0]; i < region[1]; i++ {
+			expected = append(expected, float64(i))
+		}
+	}
+	for i, v := range expected {
+		assert.Equal(t, v, samples[i][0], "sample %d", i)
+	}
+	assert.Equal(t, 0.0, samples[len(expected)][0])
+	assert.Equal(t, "seek", <-loops)
+	assert.Equal(t, "seek", <-loops)
+	assert.Equal(t, "seek", <-ended)
+
+	// Streams that can't be sought are opened again ahead of time
+	var opened atomic.Int32
+	err = dj.Add("reopen", &test_utils.Ramp{Length: 200, Live: true}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Loop: LoopOpt{Enabled: true, Start: time.Millisecond, Reopen: func() (beep.StreamSeekCloser, error) {
+			opened.Add(1)
+			return &test_utils.Ramp{Length: 200, Live: true}, nil
+		}},
+	})
+	assert.NoError(t, err)
+	time.Sleep(50 * time.Millisecond)
+	samples = test_utils.GetSamples(t, dj, 300)
+	assert.Equal(t, 199.0, samples[199][0])
+	assert.Equal(t, 48.0, samples[200][0])
+	assert.Equal(t, 147.0, samples[299][0])
+	assert.Equal(t, 1, dj.Tracks()["reopen"].Loops)
+	// The next iteration is opened ahead of time
+	assert.Eventually(t, func() bool { return opened.Load() == 2 }, time.Second, time.Millisecond)
+	// Once disabled, the track plays on until its end
+	assert.NoError(t, dj.SetLooping("reopen", false))
+	samples = test_utils.GetSamples(t, dj, 100)
+	assert.Equal(t, 148.0, samples[0][0])
+	assert.Equal(t, 199.0, samples[51][0])
+	assert.Equal(t, 0.0, samples[52][0])
+
+	// A loop region past the end of the stream has nothing to play, the track ends instead of starting over endlessly
+	err = dj.Add("empty", &test_utils.Ramp{Length: 100}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Loop:  LoopOpt{Enabled: true, Start: 5 * time.Millisecond},
+		OnEnd: func(id string) { ended <- id },
+	})
+	assert.NoError(t, err)
+	samples = test_utils.GetSamples(t, dj, 200)
+	assert.Equal(t, 99.0, samples[99][0])
+	assert.Equal(t, 0.0, samples[100][0])
+	assert.Equal(t, "empty", <-ended)
+}
+
+// New streams are only opened for streams which can't be sought back, and only while the track starts over again
+func TestDiscJockey_LoopReopen(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	var opened atomic.Int32
+	reopen := func(delay time.Duration) func() (beep.StreamSeekCloser, error) {
+		return func() (beep.StreamSeekCloser, error) {
+			opened.Add(1)
+			time.Sleep(delay)
+			return &test_utils.Ramp{Length: 200, Live: true}, nil
+		}
+	}
+	err := dj.Add("seekable", &test_utils.Ramp{Length: 200}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Loop: LoopOpt{Enabled: true, Count: 2, Start: time.Millisecond, Reopen: reopen(0)},
+	})
+	assert.NoError(t, err)
+	samples := test_utils.GetSamples(t, dj, 500)
+	assert.Equal(t, 48.0, samples[200][0])
+	assert.Equal(t, 48.0, samples[352][0])
+	assert.Equal(t, 2, dj.Tracks()["seekable"].Loops)
+	assert.Equal(t, int32(0), opened.Load())
+
+	// A single stream is opened for a track starting over once, and the track waits for it
+	err = dj.Add("wait", &test_utils.Ramp{Length: 200, Live: true}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Loop: LoopOpt{Enabled: true, Count: 1, Start: time.Millisecond, Reopen: reopen(20 * time.Millisecond), Wait: true},
+	})
+	assert.NoError(t, err)
+	samples = test_utils.GetSamples(t, dj, 400)
+	assert.Equal(t, 199.0, samples[199][0])
+	assert.Equal(t, 48.0, samples[200][0])
+	assert.Equal(t, 199.0, samples[351][0])
+	assert.Equal(t, 0.0, samples[352][0])
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(1), opened.Load())
+}
+
+func TestDiscJockey_Seek(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	assert.NoError(t, dj.Add("seekable", &test_utils.Ramp{Length: 200}, beep.Format{SampleRate: 48000}, AddTrackOpt{}))
+	assert.NoError(t, dj.Add("live", &test_utils.Ramp{Length: 200, Live: true}, beep.Format{SampleRate: 48000}, AddTrackOpt{}))
+	assert.NoError(t, dj.RampVolume("seekable", -6, 0))
+	assert.NoError(t, dj.Seek("seekable", 2*time.Millisecond))
+	assert.Error(t, dj.Seek("live", 2*time.Millisecond))
+	assert.ErrorIs(t, dj.Seek("unknown", 0), ErrTrackNotFound)
+	assert.Equal(t, 2*time.Millisecond, dj.Tracks()["seekable"].Position)
+	volume, err := dj.GetVolume("seekable")
+	assert.NoError(t, err)
+	assert.Equal(t, -6.0, volume)
+}
+
+func TestDiscJockey_Replace(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("live", &test_utils.Ramp{Length: 200, Live: true}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Loop: LoopOpt{Enabled: true, Count: 2, Reopen: func() (beep.StreamSeekCloser, error) {
+			return &test_utils.Ramp{Length: 200, Live: true}, nil
+		}, Wait: true},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, dj.RampVolume("live", -6, 0))
+	test_utils.GetSamples(t, dj, 250)
+	assert.Equal(t, 1, dj.Tracks()["live"].Loops)
+
+	// The new stream counts its own position from 0, the track keeps its volume and loops
+	assert.NoError(t, dj.Replace("live", &test_utils.Ramp{Length: 104, Live: true}, 2*time.Millisecond))
+	assert.ErrorIs(t, dj.Replace("unknown", &test_utils.Ramp{}, 0), ErrTrackNotFound)
+	info := dj.Tracks()["live"]
+	assert.Equal(t, 2*time.Millisecond, info.Position)
+	assert.Equal(t, 1, info.Loops)
+	assert.Equal(t, -6.0, info.VolumeDb)
+	test_utils.GetSamples(t, dj, 105)
+	assert.Equal(t, 2, dj.Tracks()["live"].Loops)
+}
+
+// A stream opened from an offset, counting its position from 0, reaches the end of the loop region in time
+func TestDiscJockey_Offset(t *testing.T) {
+	dj := NewDiscJockey(48000)
+	err := dj.Add("offset", &test_utils.Ramp{Length: 200}, beep.Format{SampleRate: 48000}, AddTrackOpt{
+		Offset: time.Millisecond,
+		Loop:   LoopOpt{Enabled: true, Count: 1, End: 2 * time.Millisecond},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Millisecond, dj.Tracks()["offset"].Position)
+	samples := test_utils.GetSamples(t, dj, 49)
+	assert.Equal(t, 47.0, samples[47][0])
+	assert.Equal(t, 0.0, samples[48][0])
+	assert.Equal(t, 1, dj.Tracks()["offset"].Loops)
+}
+
+// A constant stream that takes 10µs to produce each sample
+type slowStreamer struct {
+	*test_utils.Constant
+}
+
+func (s *slowStreamer) Stream(samples [][2]float64) (n int, ok bool) {
+	time.Sleep(time.Duration(len(samples)) * 10 * time.Microsecond)
+	return s.Constant.Stream(samples)
+}
+
 type MockStreamer struct {
 	mock.Mock
 }
@@ -0,0 +1,68 @@
+package disc_jockey
+
+import (
+	"github.com/faiface/beep"
+	"math"
+	"time"
+)
+
+// Time it takes for the level of the sidechain to fall once it gets quieter. This smooths the level of the
+// effects and voices over their periods, so that the music doesn't flutter at each zero crossing
+const detectorRelease = 50 * time.Millisecond
+
+// ducker lowers the music while the sidechain, made of the effect and voice tracks, is louder than a threshold.
+// The gain of the music moves towards its target smoothly, at the pace of the attack when ducking,
+// and of the release when coming back
+type ducker struct {
+	opt DuckingOpt
+	// Linear values of the threshold and of the attenuation
+	threshold, attenuation float64
+	// Smoothing coefficients applied per sample
+	attack, release, detectorDecay float64
+	// Peak level of the sidechain
+	level float64
+	// Gain currently applied to the music
+	gain float64
+}
+
+func newDucker(opt DuckingOpt, sampleRate beep.SampleRate) *ducker {
+	d := &ducker{gain: 1, detectorDecay: math.Exp(-1 / float64(max(1, sampleRate.N(detectorRelease))))}
+	d.set(opt, sampleRate)
+	return d
+}
+
+// set changes the settings of the ducker. The music moves to its new level smoothly
+func (d *ducker) set(opt DuckingOpt, sampleRate beep.SampleRate) {
+	d.opt = opt
+	d.threshold = math.Pow(10, opt.ThresholdDb/20)
+	d.attenuation = math.Pow(10, opt.AttenuationDb/20)
+	d.attack = smoothing(sampleRate.N(opt.Attack))
+	d.release = smoothing(sampleRate.N(opt.Release))
+}
+
+// process applies the ducking gain to the music, according to the level of the sidechain
+func (d *ducker) process(music, sidechain [][2]float64) {
+	for i := range music {
+		peak := max(math.Abs(sidechain[i][0]), math.Abs(sidechain[i][1]))
+		d.level = max(peak, d.level*d.detectorDecay)
+		target := 1.
+		if d.opt.Enabled && d.level > d.threshold {
+			target = d.attenuation
+		}
+		if target < d.gain {
+			d.gain += (target - d.gain) * d.attack
+		} else {
+			d.gain += (target - d.gain) * d.release
+		}
+		music[i][0] *= d.gain
+		music[i][1] *= d.gain
+	}
+}
+
+// smoothing returns the coefficient of a one-pole filter settling in about n samples, 1 being immediate
+func smoothing(n int) float64 {
+	if n <= 0 {
+		return 1
+	}
+	return 1 - math.Exp(-1/float64(n))
+}
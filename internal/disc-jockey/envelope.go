@@ -0,0 +1,98 @@
+package disc_jockey
+
+import (
+	"github.com/faiface/beep"
+	"math"
+)
+
+// envelope applies a gain moving linearly from one value to another over a fixed number of samples.
+// Once the ramp is over, the final gain is held until a new ramp is requested
+type envelope struct {
+	Streamer beep.Streamer
+	// Values at the start and at the end of the ramp
+	from, to float64
+	// When true, values are expressed in decibels and interpolated as such,
+	// otherwise they are plain linear gains
+	decibels bool
+	// Current position in the ramp and length of the ramp, both in samples
+	pos, length int
+	// Called once, from the audio thread, when the ramp is over
+	onDone func()
+}
+
+// newEnvelope creates a linear gain envelope ramping from "from" to "to" over "length" samples
+func newEnvelope(s beep.Streamer, from, to float64, length int) *envelope {
+	return &envelope{Streamer: s, from: from, to: to, length: length}
+}
+
+// newDbEnvelope creates an envelope interpolating in decibels, starting at 0dB (no change)
+func newDbEnvelope(s beep.Streamer) *envelope {
+	return &envelope{Streamer: s, decibels: true}
+}
+
+func (e *envelope) Stream(samples [][2]float64) (n int, ok bool) {
+	n, ok = e.Streamer.Stream(samples)
+	// Nothing to do once the ramp is over if it settled on a unity gain
+	if e.pos >= e.length && e.gain() == 1 {
+		return n, ok
+	}
+	for i := range samples[:n] {
+		gain := e.gain()
+		samples[i][0] *= gain
+		samples[i][1] *= gain
+		if e.pos < e.length {
+			e.pos++
+			if e.pos == e.length {
+				e.done()
+			}
+		}
+	}
+	return n, ok
+}
+
+func (e *envelope) Err() error {
+	return e.Streamer.Err()
+}
+
+// value returns the current value of the ramp, either a linear gain or decibels
+func (e *envelope) value() float64 {
+	if e.pos >= e.length {
+		return e.to
+	}
+	return e.from + (e.to-e.from)*float64(e.pos)/float64(e.length)
+}
+
+// gain returns the linear gain to apply to the current sample
+func (e *envelope) gain() float64 {
+	if e.decibels {
+		return math.Pow(10, e.value()/20)
+	}
+	return e.value()
+}
+
+// rampTo starts a new ramp from the current value to the target value over "length" samples.
+// A length of 0 applies the target value immediately
+func (e *envelope) rampTo(to float64, length int, onDone func()) {
+	e.ramp(e.value(), to, length, onDone)
+}
+
+// ramp starts a new ramp between two arbitrary values over "length" samples
+func (e *envelope) ramp(from, to float64, length int, onDone func()) {
+	e.from = from
+	e.to = to
+	e.pos = 0
+	e.length = length
+	e.onDone = onDone
+	if length <= 0 {
+		e.done()
+	}
+}
+
+func (e *envelope) done() {
+	if e.onDone == nil {
+		return
+	}
+	cb := e.onDone
+	e.onDone = nil
+	cb()
+}
@@ -0,0 +1,304 @@
+package disc_jockey
+
+import (
+	"errors"
+	"fmt"
+	"github.com/faiface/beep"
+	"log/slog"
+	"sync"
+)
+
+// Number of samples skipped at once when moving a stream forward
+const skipBlock = 512
+
+// looper plays the original stream of a track, and starts it over gaplessly when looping.
+// Once the end of the loop region is reached, the next sample is the start of the region. The stream is sought back
+// when it can be. Otherwise, it goes on with a new stream of the same asset, opened and moved to the start
+// of the region in the background ahead of time
+type looper struct {
+	s beep.StreamSeekCloser
+	// Position in the current stream, counted as samples are streamed from the initial position of the stream
+	pos     int
+	started bool
+	// Position the original stream starts at, when it counts its own position from 0
+	offset int
+	// Loop region, in samples of the original stream. An end of 0 is the end of the stream
+	start, end int
+	enabled    bool
+	// Number of times the track may start over, 0 meaning forever, and number of times it did so far
+	count, loops int
+	reopen       func() (beep.StreamSeekCloser, error)
+	// The stream is sought back to start over. New streams are only opened for streams which can't be sought
+	seekable bool
+	// Waits for the stream of the next iteration instead of being silent until it is ready
+	wait bool
+	// Called each time the track starts over
+	onLoop func()
+	// The end of an iteration has been reached, the track is silent until the next one is ready
+	waiting bool
+	// Nothing has been streamed since the track started over
+	idle bool
+
+	// Guards the stream of the next iteration, opened in the background
+	mu       sync.Mutex
+	next     beep.StreamSeekCloser
+	nextErr  error
+	fetching bool
+	// Closed once the stream being opened is ready, if any
+	fetched chan struct{}
+	closed  bool
+}
+
+func newLooper(s beep.StreamSeekCloser, opt LoopOpt, offset int, sampleRate beep.SampleRate, onLoop func()) *looper {
+	l := &looper{
+		s:       s,
+		offset:  offset,
+		start:   sampleRate.N(opt.Start),
+		end:     sampleRate.N(opt.End),
+		enabled: opt.Enabled,
+		count:   opt.Count,
+		reopen:  opt.Reopen,
+		wait:    opt.Wait,
+		onLoop:  onLoop,
+	}
+	// Seeking the stream where it already is tells whether it can be sought at all
+	l.seekable = l.reopen == nil || seekExact(s, s.Position()) == nil
+	if l.enabled {
+		l.prefetch()
+	}
+	return l
+}
+
+func (l *looper) Stream(samples [][2]float64) (n int, ok bool) {
+	if !l.started {
+		l.pos, l.started = l.initialPos(), true
+	}
+	for n < len(samples) {
+		if !l.waiting {
+			toRead := samples[n:]
+			if l.looping() && l.end > 0 {
+				toRead = toRead[:min(len(toRead), max(l.end-l.pos, 0))]
+			}
+			if len(toRead) > 0 {
+				sn, sok := l.s.Stream(toRead)
+				l.pos += sn
+				n += sn
+				if sn > 0 {
+					l.idle = false
+				}
+				if sok && sn > 0 {
+					continue
+				}
+			}
+			// The end of the iteration is reached
+			if !l.looping() {
+				return n, n > 0
+			}
+			// An empty loop region would start over endlessly
+			if l.idle {
+				slog.Error("[Disc Jockey] :: Track has nothing to play after starting over, ending it")
+				return n, n > 0
+			}
+			if l.seekable && !l.seekBack() {
+				if l.reopen == nil {
+					return n, n > 0
+				}
+				// New streams are opened from now on
+				l.seekable = false
+			}
+			l.waiting = true
+		}
+		ready, err := l.startOver()
+		if err != nil {
+			slog.Error(fmt.Sprintf("[Disc Jockey] :: Track can't start over, ending it : %v", err))
+			return n, n > 0
+		}
+		if !ready {
+			// The next iteration is being opened, the track is silent meanwhile
+			for i := range samples[n:] {
+				samples[n+i] = [2]float64{}
+			}
+			return len(samples), true
+		}
+	}
+	return n, true
+}
+
+// looping returns whether the track starts over once the end of the loop region is reached
+func (l *looper) looping() bool {
+	return l.enabled && (l.count == 0 || l.loops < l.count)
+}
+
+// seekBack moves the current stream back to the start of the loop region, returning false if it can't be done
+func (l *looper) seekBack() bool {
+	if err := seekExact(l.s, l.start); err != nil {
+		slog.Warn(fmt.Sprintf("[Disc Jockey] :: Track can't be sought back to start over : %v", err))
+		return false
+	}
+	l.pos, l.started = l.start, true
+	return true
+}
+
+// startOver starts the next iteration, as soon as its stream is ready. A stream sought back is ready right away
+func (l *looper) startOver() (ready bool, err error) {
+	if !l.seekable {
+		next, err := l.takeNext()
+		if err == nil && next == nil {
+			l.prefetch()
+			if l.wait {
+				next, err = l.takeNext()
+			}
+		}
+		if err != nil {
+			return false, err
+		}
+		if next == nil {
+			return false, nil
+		}
+		l.s.Close()
+		l.s, l.pos = next, l.start
+	}
+	l.loops++
+	l.waiting, l.idle = false, true
+	// The stream of the iteration after this one, if any, is opened ahead of time
+	l.prefetch()
+	if l.onLoop != nil {
+		l.onLoop()
+	}
+	return true, nil
+}
+
+// takeNext returns the stream of the next iteration if it is ready, or the reason why it couldn't be opened.
+// When waiting, a stream being opened is waited for
+func (l *looper) takeNext() (beep.StreamSeekCloser, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.wait && l.fetching {
+		fetched := l.fetched
+		l.mu.Unlock()
+		<-fetched
+		l.mu.Lock()
+	}
+	next, err := l.next, l.nextErr
+	l.next, l.nextErr = nil, nil
+	return next, err
+}
+
+// prefetch opens the stream of the next iteration in the background, if the track starts over again
+// and can't be sought back, and the stream is not already opened
+func (l *looper) prefetch() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.seekable || !l.looping() || l.fetching || l.next != nil || l.nextErr != nil || l.closed {
+		return
+	}
+	l.fetching = true
+	fetched := make(chan struct{})
+	l.fetched = fetched
+	go func() {
+		defer close(fetched)
+		s, err := l.reopen()
+		if err == nil {
+			err = skip(s, l.start)
+			if err != nil {
+				s.Close()
+			}
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.fetching = false
+		if l.closed {
+			if err == nil {
+				s.Close()
+			}
+			return
+		}
+		if err != nil {
+			l.nextErr = err
+			return
+		}
+		l.next = s
+	}()
+}
+
+// setLooping enables or disables looping. Once disabled, the track plays on until its end
+func (l *looper) setLooping(enabled bool) {
+	l.enabled = enabled
+	if enabled {
+		l.prefetch()
+	}
+}
+
+func (l *looper) Err() error {
+	return l.s.Err()
+}
+
+func (l *looper) Len() int {
+	return l.s.Len()
+}
+
+// Position returns the position in the original stream, which moves back to the start of the loop region on each loop
+func (l *looper) Position() int {
+	if !l.started {
+		return l.initialPos()
+	}
+	return l.pos
+}
+
+// initialPos returns the position of the original stream before anything is streamed
+func (l *looper) initialPos() int {
+	return max(l.s.Position(), l.offset)
+}
+
+// Seek moves the current stream to the given sample. A track waiting for its next iteration goes on from there instead
+func (l *looper) Seek(p int) error {
+	if err := seekExact(l.s, p); err != nil {
+		return err
+	}
+	l.pos, l.started, l.waiting = p, true, false
+	return nil
+}
+
+// replace goes on with a new stream, starting at the given position of the original stream.
+// The number of loops so far, and the stream opened for the next iteration, are kept
+func (l *looper) replace(s beep.StreamSeekCloser, offset int) {
+	l.s.Close()
+	l.s, l.offset = s, offset
+	l.pos, l.started, l.waiting = 0, false, false
+}
+
+func (l *looper) Close() error {
+	l.mu.Lock()
+	l.closed = true
+	if l.next != nil {
+		l.next.Close()
+		l.next = nil
+	}
+	l.mu.Unlock()
+	return l.s.Close()
+}
+
+// seekExact moves a stream to the given sample. Decoders may land on the start of the frame holding this sample,
+// the rest of the frame is then skipped
+func seekExact(s beep.StreamSeekCloser, p int) error {
+	if err := s.Seek(p); err != nil {
+		return err
+	}
+	return skip(s, p-s.Position())
+}
+
+// skip moves a stream forward by reading n samples
+func skip(s beep.Streamer, n int) error {
+	skipped := make([][2]float64, skipBlock)
+	for n > 0 {
+		sn, ok := s.Stream(skipped[:min(n, len(skipped))])
+		n -= sn
+		if !ok {
+			if err := s.Err(); err != nil {
+				return err
+			}
+			return errors.New("stream ended while skipping samples")
+		}
+	}
+	return nil
+}
@@ -10,7 +10,6 @@ import (
 	test_utils "live-audio-mixer/test-utils"
 	"os"
 	"path/filepath"
-	"syscall"
 	"testing"
 	"time"
 )
@@ -18,7 +17,7 @@ import (
 // This test suite is meant to test a live manipulation/recording of audio
 
 func TestLive_MixedTracks(t *testing.T) {
-	dj := disc_jockey.NewDiscJockey()
+	dj := disc_jockey.NewDiscJockey(48000)
 	chicken := test_utils.OpenMp3Resource(t, test_utils.Mp3_Chicken)
 	bg := test_utils.OpenMp3Resource(t, test_utils.Mp3_BgMusic)
 	quack := test_utils.OpenMp3Resource(t, test_utils.Mp3_Quack)
@@ -30,25 +29,21 @@ func TestLive_MixedTracks(t *testing.T) {
 	err := dj.Add("chicken", chicken, beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}, disc_jockey.AddTrackOpt{})
 	assert.NoError(t, err)
 	//speaker.Play(dj)
-	done := make(chan os.Signal, 1)
 
-	go func(done chan os.Signal) {
-		select {
-		case <-time.After(5 * time.Second):
+	// The encoder doesn't pace its input, the tracks are switched after 5s of mix rather than 5s of wall clock
+	fiveSecs := format.SampleRate.N(5 * time.Second)
+	mix := beep.Seq(
+		beep.Take(fiveSecs, dj),
+		beep.Callback(func() {
 			err = dj.Remove("chicken")
 			assert.NoError(t, err)
 			err = dj.Add("bg", bg, beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}, disc_jockey.AddTrackOpt{})
 			assert.NoError(t, err)
 			err = dj.Add("quack", quack, beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}, disc_jockey.AddTrackOpt{})
 			assert.NoError(t, err)
-
-		}
-
-		select {
-		case <-time.After(5 * time.Second):
-			done <- syscall.SIGINT
-		}
-	}(done)
+		}),
+		beep.Take(fiveSecs, dj),
+	)
 
 	tmpDir, err := os.MkdirTemp("", "lam-live-recording")
 	fmt.Println("Temp dir is : " + tmpDir)
@@ -60,7 +55,7 @@ func TestLive_MixedTracks(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	err = rt_encoder.FFEncode(testFile, dj, format, done)
+	err = rt_encoder.FFEncode(testFile, mix, format, make(chan os.Signal, 1))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,153 @@
+package live_stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+const (
+	// Size of the fixed part of an Ogg page header
+	oggHeaderSize = 27
+	// Number of pages a listener can lag behind before being dropped
+	listenerBacklog = 64
+)
+
+var oggCapturePattern = []byte("OggS")
+
+// Broadcaster receives an encoded Ogg stream and forwards it to any number of listeners.
+// The stream is split into Ogg pages, so that a listener joining at any time receives
+// the stream headers first, and then complete pages only
+type Broadcaster struct {
+	mu sync.Mutex
+	// Bytes received but not yet forming a complete page
+	pending []byte
+	// Header pages of the stream, sent first to every new listener
+	headers [][]byte
+	// Whether all the header pages have been received
+	headersDone bool
+	listeners   map[chan []byte]struct{}
+	closed      bool
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		listeners: map[chan []byte]struct{}{},
+	}
+}
+
+// Write implements io.Writer. Writing never fails, so that a broadcasting issue cannot stop the recording.
+func (b *Broadcaster) Write(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.pending = append(b.pending, p...)
+	for {
+		page, ok := b.nextPage()
+		if !ok {
+			break
+		}
+		b.broadcast(page)
+	}
+	return len(p), nil
+}
+
+// Seek implements io.Seeker so that a broadcaster can be used as an encoder destination.
+// A live stream cannot be rewound, any seek fails
+func (b *Broadcaster) Seek(offset int64, whence int) (int64, error) {
+	return 0, fmt.Errorf("a live stream is not seekable")
+}
+
+// Subscribe registers a new listener. The returned channel receives complete Ogg pages
+// and is closed when the broadcast ends or when the listener is too slow to keep up.
+// The returned function must be called to unsubscribe
+func (b *Broadcaster) Subscribe() (<-chan []byte, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ch := make(chan []byte, listenerBacklog+len(b.headers))
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	for _, header := range b.headers {
+		ch <- header
+	}
+	b.listeners[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.drop(ch)
+	}
+}
+
+// Close ends the broadcast for all listeners
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.listeners {
+		b.drop(ch)
+	}
+}
+
+// nextPage extracts the next complete Ogg page from the pending bytes, if any
+func (b *Broadcaster) nextPage() ([]byte, bool) {
+	// Resynchronize on the capture pattern, discarding anything before it
+	start := bytes.Index(b.pending, oggCapturePattern)
+	if start < 0 {
+		// Keep the last bytes, as they could be the start of a capture pattern
+		if len(b.pending) > len(oggCapturePattern) {
+			b.pending = b.pending[len(b.pending)-len(oggCapturePattern):]
+		}
+		return nil, false
+	}
+	b.pending = b.pending[start:]
+	if len(b.pending) < oggHeaderSize {
+		return nil, false
+	}
+	nSegments := int(b.pending[26])
+	if len(b.pending) < oggHeaderSize+nSegments {
+		return nil, false
+	}
+	size := oggHeaderSize + nSegments
+	for _, segment := range b.pending[oggHeaderSize : oggHeaderSize+nSegments] {
+		size += int(segment)
+	}
+	if len(b.pending) < size {
+		return nil, false
+	}
+	page := make([]byte, size)
+	copy(page, b.pending[:size])
+	b.pending = b.pending[size:]
+	return page, true
+}
+
+// broadcast sends a page to every listener, dropping the ones lagging behind
+func (b *Broadcaster) broadcast(page []byte) {
+	// Header pages all have a granule position of 0. The first page with a
+	// non-zero granule position is the first audio page
+	if !b.headersDone {
+		if binary.LittleEndian.Uint64(page[6:14]) == 0 {
+			b.headers = append(b.headers, page)
+		} else {
+			b.headersDone = true
+		}
+	}
+	for ch := range b.listeners {
+		select {
+		case ch <- page:
+		default:
+			slog.Warn("[Broadcaster] :: A listener is too slow to keep up with the stream, dropping it")
+			b.drop(ch)
+		}
+	}
+}
+
+func (b *Broadcaster) drop(ch chan []byte) {
+	if _, ok := b.listeners[ch]; !ok {
+		return
+	}
+	delete(b.listeners, ch)
+	close(ch)
+}
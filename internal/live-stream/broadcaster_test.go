@@ -0,0 +1,96 @@
+package live_stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Build a fake Ogg page with a single segment
+func makePage(granule uint64, body string) []byte {
+	page := make([]byte, oggHeaderSize+1)
+	copy(page, oggCapturePattern)
+	binary.LittleEndian.PutUint64(page[6:14], granule)
+	page[26] = 1
+	page[27] = byte(len(body))
+	return append(page, body...)
+}
+
+func TestBroadcaster_LateListener(t *testing.T) {
+	b := NewBroadcaster()
+	head, tags := makePage(0, "OpusHead"), makePage(0, "OpusTags")
+	audio1, audio2 := makePage(960, "audio1"), makePage(1920, "audio2")
+
+	// Pages can be split across writes
+	stream := bytes.Join([][]byte{head, tags, audio1, audio2}, nil)
+	_, err := b.Write(stream[:10])
+	assert.NoError(t, err)
+	_, err = b.Write(stream[10 : len(head)+len(tags)+len(audio1)+3])
+	assert.NoError(t, err)
+
+	// A listener joining mid-stream gets the headers first, then the next complete pages
+	pages, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	_, err = b.Write(stream[len(head)+len(tags)+len(audio1)+3:])
+	assert.NoError(t, err)
+	assert.Equal(t, head, <-pages)
+	assert.Equal(t, tags, <-pages)
+	assert.Equal(t, audio2, <-pages)
+
+	b.Close()
+	_, ok := <-pages
+	assert.False(t, ok)
+}
+
+func TestBroadcaster_DropSlowListener(t *testing.T) {
+	b := NewBroadcaster()
+	pages, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	for i := 0; i <= listenerBacklog; i++ {
+		_, err := b.Write(makePage(uint64(i+1), "audio"))
+		assert.NoError(t, err)
+	}
+	received := 0
+	for range pages {
+		received++
+	}
+	assert.Equal(t, listenerBacklog, received)
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	b := NewBroadcaster()
+	_, err := b.Write(makePage(0, "OpusHead"))
+	assert.NoError(t, err)
+	h := NewHandler(func(id string) (*Broadcaster, error) {
+		if id != "rec" {
+			return nil, assert.AnError
+		}
+		return b, nil
+	})
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + LivePath + "unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	res, err = http.Get(server.URL + LivePath + "rec")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "audio/ogg", res.Header.Get("Content-Type"))
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = b.Write(makePage(960, "audio"))
+		b.Close()
+	}()
+	body := new(bytes.Buffer)
+	_, err = body.ReadFrom(bufio.NewReader(res.Body))
+	assert.NoError(t, err)
+	assert.Equal(t, append(makePage(0, "OpusHead"), makePage(960, "audio")...), body.Bytes())
+}
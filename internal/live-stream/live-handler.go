@@ -0,0 +1,68 @@
+package live_stream
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Path under which live streams are served, followed by the record ID
+const LivePath = "/live/"
+
+// BroadcasterLookup retrieves the broadcaster of a record from its ID
+type BroadcasterLookup func(id string) (*Broadcaster, error)
+
+// Handler serves the live stream of each record over HTTP, at /live/<record id>.
+// The stream is sent as a never ending chunked Ogg response, which most players can read directly
+type Handler struct {
+	lookup BroadcasterLookup
+}
+
+func NewHandler(lookup BroadcasterLookup) *Handler {
+	return &Handler{lookup: lookup}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, LivePath)
+	if id == "" || id == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+	b, err := h.lookup(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	flusher, canFlush := w.(http.Flusher)
+
+	pages, unsubscribe := b.Subscribe()
+	defer unsubscribe()
+	slog.Info(fmt.Sprintf("[Live Handler] :: New listener %s on record %s", r.RemoteAddr, id))
+
+	w.Header().Set("Content-Type", "audio/ogg")
+	w.Header().Set("Cache-Control", "no-cache, no-store")
+	w.WriteHeader(http.StatusOK)
+	for {
+		select {
+		case page, ok := <-pages:
+			if !ok {
+				return
+			}
+			if _, err := w.Write(page); err != nil {
+				slog.Debug(fmt.Sprintf("[Live Handler] :: Listener %s on record %s left : %v", r.RemoteAddr, id, err))
+				return
+			}
+			if canFlush {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			slog.Info(fmt.Sprintf("[Live Handler] :: Listener %s on record %s disconnected", r.RemoteAddr, id))
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package loudness
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultCacheSize is the number of assets a cache holds the loudness of by default
+const DefaultCacheSize = 10000
+
+// Cache holds the loudness of assets, so that each asset is only measured once.
+// Concurrent requests for an asset being measured wait for the measure in progress.
+// Once the cache is full, the least recently used assets are forgotten
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	// Keys of the assets, the most recently used first
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type entry struct {
+	key string
+	// Closed once the measure is over
+	done chan struct{}
+	lufs float64
+	err  error
+}
+
+// NewCache creates a cache holding the loudness of at most maxEntries assets, 0 meaning DefaultCacheSize.
+// Keys are expected to change along with the content of the assets, such as their URL and version
+func NewCache(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultCacheSize
+	}
+	return &Cache{maxEntries: maxEntries, lru: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Get returns the loudness of an asset, calling measure if it is not known yet.
+// Failed measures are not kept, the next request measures the asset again
+func (c *Cache) Get(key string, measure func() (float64, error)) (float64, error) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		c.mu.Unlock()
+		e := elem.Value.(*entry)
+		<-e.done
+		return e.lufs, e.err
+	}
+	e := &entry{key: key, done: make(chan struct{})}
+	c.entries[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+	c.mu.Unlock()
+
+	e.lufs, e.err = measure()
+	if e.err != nil {
+		c.mu.Lock()
+		// The entry may have been evicted, and the asset measured again meanwhile
+		if elem, ok := c.entries[key]; ok && elem.Value == e {
+			c.remove(elem)
+		}
+		c.mu.Unlock()
+	}
+	close(e.done)
+	return e.lufs, e.err
+}
+
+// Lookup returns the loudness of an asset if it has already been measured, without waiting for a measure in progress
+func (c *Cache) Lookup(key string) (float64, bool) {
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if ok {
+		c.lru.MoveToFront(elem)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+	e := elem.Value.(*entry)
+	select {
+	case <-e.done:
+		return e.lufs, e.err == nil
+	default:
+		return 0, false
+	}
+}
+
+// remove forgets an asset, the lock must be held
+func (c *Cache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*entry).key)
+}
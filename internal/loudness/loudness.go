@@ -0,0 +1,172 @@
+package loudness
+
+import (
+	"github.com/faiface/beep"
+	"math"
+)
+
+const (
+	// Gating blocks last 400ms, and overlap by 75%
+	blockSteps = 4
+	stepLength = 0.1
+	// Blocks quieter than this are ignored altogether, in LUFS
+	absoluteGate = -70.
+	// Blocks quieter than the loudness of the louder blocks minus this are ignored, in LU
+	relativeGate = -10.
+	// Number of samples measured at once
+	measureBlock = 4096
+)
+
+// Meter measures the integrated loudness of a stereo signal, as defined by EBU R128 (ITU-R BS.1770).
+// Samples are K-weighted, their power is computed over 400ms blocks, and the blocks are gated
+// so that silent parts don't weigh in the result
+type Meter struct {
+	// K-weighting filters, one pair per channel
+	shelf, highPass [2]biquad
+	// Number of samples in a 100ms step
+	stepSize int
+	// Sum of the power of the samples of the current step, and number of samples summed so far
+	stepSum   float64
+	stepCount int
+	// Mean power of the last steps, the last of them being steps[(next-1)%blockSteps]
+	steps [blockSteps]float64
+	next  int
+	// Mean power of every gating block measured so far
+	blocks []float64
+}
+
+// NewMeter creates a meter for a signal at the given sample rate
+func NewMeter(sampleRate beep.SampleRate) *Meter {
+	fs := float64(sampleRate)
+	m := &Meter{stepSize: max(1, int(stepLength*fs))}
+	shelf, highPass := shelfFilter(fs), highPassFilter(fs)
+	for c := range m.shelf {
+		m.shelf[c], m.highPass[c] = shelf, highPass
+	}
+	return m
+}
+
+// Write adds samples to the measure
+func (m *Meter) Write(samples [][2]float64) {
+	for _, sample := range samples {
+		for c, v := range sample {
+			v = m.highPass[c].process(m.shelf[c].process(v))
+			m.stepSum += v * v
+		}
+		m.stepCount++
+		if m.stepCount < m.stepSize {
+			continue
+		}
+		m.steps[m.next%blockSteps] = m.stepSum / float64(m.stepCount)
+		m.next++
+		m.stepSum, m.stepCount = 0, 0
+		if m.next < blockSteps {
+			continue
+		}
+		power := 0.
+		for _, p := range m.steps {
+			power += p
+		}
+		m.blocks = append(m.blocks, power/blockSteps)
+	}
+}
+
+// Integrated returns the loudness of the samples written so far, in LUFS.
+// It is -Inf for a signal shorter than a block, or silent
+func (m *Meter) Integrated() float64 {
+	absolute := gatedMean(m.blocks, power(absoluteGate))
+	if absolute == 0 {
+		return math.Inf(-1)
+	}
+	return lufs(gatedMean(m.blocks, absolute*power(relativeGate)))
+}
+
+// Measure reads a whole stream and returns its integrated loudness in LUFS
+func Measure(s beep.Streamer, sampleRate beep.SampleRate) (float64, error) {
+	m := NewMeter(sampleRate)
+	samples := make([][2]float64, measureBlock)
+	for {
+		n, ok := s.Stream(samples)
+		m.Write(samples[:n])
+		if !ok {
+			break
+		}
+	}
+	return m.Integrated(), s.Err()
+}
+
+// gatedMean returns the mean power of the blocks louder than the gate, 0 if there is none
+func gatedMean(blocks []float64, gate float64) float64 {
+	sum, n := 0., 0
+	for _, p := range blocks {
+		if p > gate {
+			sum += p
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// lufs converts a K-weighted power to a loudness
+func lufs(power float64) float64 {
+	return -0.691 + 10*math.Log10(power)
+}
+
+// power converts a loudness to a K-weighted power
+func power(lufs float64) float64 {
+	return math.Pow(10, (lufs+0.691)/10)
+}
+
+// biquad is a second order IIR filter, in direct form I
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x1, f.x2 = x, f.x1
+	f.y1, f.y2 = y, f.y1
+	return y
+}
+
+// shelfFilter is the first stage of the K-weighting, a high shelf modeling the acoustic effect of the head.
+// BS.1770 only gives coefficients at 48kHz, they are derived here from the analog prototype for any sample rate
+func shelfFilter(fs float64) biquad {
+	const (
+		f0   = 1681.974450955533
+		gain = 3.999843853973347
+		q    = 0.7071752369554196
+	)
+	k := math.Tan(math.Pi * f0 / fs)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	return biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+}
+
+// highPassFilter is the second stage of the K-weighting, the RLB high-pass filter
+func highPassFilter(fs float64) biquad {
+	const (
+		f0 = 38.13547087602444
+		q  = 0.5003270373238773
+	)
+	k := math.Tan(math.Pi * f0 / fs)
+	a0 := 1 + k/q + k*k
+	return biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+}
@@ -0,0 +1,108 @@
+package loudness
+
+import (
+	"errors"
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	test_utils "live-audio-mixer/test-utils"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+// sine generates a 1kHz sine of the given amplitude on both channels
+func sine(sampleRate beep.SampleRate, amplitude float64, duration time.Duration) beep.Streamer {
+	return &test_utils.Sine{Amplitude: amplitude, Frequency: 1000, SampleRate: sampleRate, Length: sampleRate.N(duration)}
+}
+
+func TestMeasure(t *testing.T) {
+	for _, sampleRate := range []beep.SampleRate{44100, 48000} {
+		// A full scale 1kHz sine is at -3.01 LUFS per channel
+		lufs, err := Measure(sine(sampleRate, 1, 5*time.Second), sampleRate)
+		assert.Nil(t, err)
+		assert.InDelta(t, 0, lufs, 0.1)
+
+		lufs, err = Measure(sine(sampleRate, 0.1, 5*time.Second), sampleRate)
+		assert.Nil(t, err)
+		assert.InDelta(t, -20, lufs, 0.1)
+	}
+}
+
+func TestMeasure_Gating(t *testing.T) {
+	sampleRate := beep.SampleRate(48000)
+	// Silence doesn't lower the loudness of a track
+	lufs, err := Measure(beep.Seq(sine(sampleRate, 0.1, 5*time.Second), beep.Silence(sampleRate.N(20*time.Second))), sampleRate)
+	assert.Nil(t, err)
+	assert.InDelta(t, -20, lufs, 0.2)
+
+	lufs, err = Measure(beep.Silence(sampleRate.N(time.Second)), sampleRate)
+	assert.Nil(t, err)
+	assert.True(t, math.IsInf(lufs, -1))
+
+	// Too short to be measured
+	lufs, err = Measure(sine(sampleRate, 1, 300*time.Millisecond), sampleRate)
+	assert.Nil(t, err)
+	assert.True(t, math.IsInf(lufs, -1))
+}
+
+func TestCache(t *testing.T) {
+	c := NewCache(0)
+	var (
+		mu       sync.Mutex
+		measured int
+		wg       sync.WaitGroup
+	)
+	started := make(chan struct{})
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lufs, err := c.Get("asset", func() (float64, error) {
+				mu.Lock()
+				measured++
+				mu.Unlock()
+				close(started)
+				time.Sleep(10 * time.Millisecond)
+				return -14, nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, -14., lufs)
+		}()
+	}
+	// A measure in progress isn't waited for
+	<-started
+	_, ok := c.Lookup("asset")
+	assert.False(t, ok)
+	wg.Wait()
+	assert.Equal(t, 1, measured)
+	lufs, ok := c.Lookup("asset")
+	assert.True(t, ok)
+	assert.Equal(t, -14., lufs)
+	_, ok = c.Lookup("unknown")
+	assert.False(t, ok)
+
+	// Failures are retried
+	_, err := c.Get("broken", func() (float64, error) { return 0, errors.New("unreachable") })
+	assert.NotNil(t, err)
+	lufs, err = c.Get("broken", func() (float64, error) { return -20, nil })
+	assert.Nil(t, err)
+	assert.Equal(t, -20., lufs)
+}
+
+func TestCache_Evict(t *testing.T) {
+	c := NewCache(2)
+	measure := func() (float64, error) { return -14, nil }
+	_, _ = c.Get("a", measure)
+	_, _ = c.Get("b", measure)
+	// The least recently used asset is forgotten
+	_, ok := c.Lookup("a")
+	assert.True(t, ok)
+	_, _ = c.Get("c", measure)
+	_, ok = c.Lookup("b")
+	assert.False(t, ok)
+	_, ok = c.Lookup("a")
+	assert.True(t, ok)
+	_, ok = c.Lookup("c")
+	assert.True(t, ok)
+}
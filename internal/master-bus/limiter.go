@@ -0,0 +1,115 @@
+package master_bus
+
+import "math"
+
+// limiter is a look-ahead peak limiter. Samples are delayed by lookAhead samples, which gives the gain the time
+// to go down smoothly before a peak comes out, so that the output never exceeds the ceiling.
+//
+// For each input sample, the gain required to keep it below the ceiling is computed. The minimum of the
+// required gains over the look-ahead window is held, released slowly once the peak is over, and then
+// smoothed by a moving average over the look-ahead window. As every value averaged for the delayed peak is
+// at most the gain it requires, so is their average
+type limiter struct {
+	lookAhead int
+	// Maximum absolute value of the output, +Inf when the limiter is disabled
+	ceiling float64
+	// Fraction of the remaining gain reduction recovered at each sample
+	release float64
+	// Samples waiting to come out
+	delay    [][2]float64
+	delayPos int
+	// Required gains over the last lookAhead+1 samples, as a monotonic queue giving their minimum
+	window minQueue
+	// Held gain, after release
+	held float64
+	// Last lookAhead held gains, and their sum for the moving average
+	smooth    []float64
+	smoothPos int
+	smoothSum float64
+	// Number of samples processed so far
+	pos int
+}
+
+func newLimiter(lookAhead int) *limiter {
+	l := &limiter{
+		lookAhead: max(lookAhead, 1),
+		ceiling:   math.Inf(1),
+		release:   1,
+		held:      1,
+	}
+	l.delay = make([][2]float64, l.lookAhead)
+	l.window = newMinQueue(l.lookAhead + 1)
+	l.smooth = make([]float64, l.lookAhead)
+	for i := range l.smooth {
+		l.smooth[i] = 1
+	}
+	l.smoothSum = float64(l.lookAhead)
+	return l
+}
+
+// process takes a new sample, and returns the sample entering the limiter lookAhead samples ago
+func (l *limiter) process(sample [2]float64) [2]float64 {
+	required := 1.0
+	if peak := math.Max(math.Abs(sample[0]), math.Abs(sample[1])); peak > l.ceiling {
+		required = l.ceiling / peak
+	}
+	l.window.expire(l.pos - l.lookAhead)
+	l.window.push(l.pos, required)
+	l.pos++
+
+	// Attack is immediate, as it is smoothed afterward. Release is exponential
+	l.held = math.Min(l.window.min(), l.held+(1-l.held)*l.release)
+	l.smoothSum += l.held - l.smooth[l.smoothPos]
+	l.smooth[l.smoothPos] = l.held
+	l.smoothPos = (l.smoothPos + 1) % l.lookAhead
+	gain := math.Min(l.smoothSum/float64(l.lookAhead), 1)
+
+	out := l.delay[l.delayPos]
+	l.delay[l.delayPos] = sample
+	l.delayPos = (l.delayPos + 1) % l.lookAhead
+	out[0], out[1] = l.clamp(out[0]*gain), l.clamp(out[1]*gain)
+	return out
+}
+
+// clamp absorbs the rounding errors of the moving average
+func (l *limiter) clamp(v float64) float64 {
+	return math.Max(-l.ceiling, math.Min(l.ceiling, v))
+}
+
+// minQueue gives the minimum of the values pushed over a sliding window, in constant amortized time
+type minQueue struct {
+	pos    []int
+	values []float64
+	// Ring buffer bounds
+	head, size int
+}
+
+func newMinQueue(capacity int) minQueue {
+	return minQueue{pos: make([]int, capacity), values: make([]float64, capacity)}
+}
+
+func (q *minQueue) push(pos int, value float64) {
+	// Values greater than the new one can't be the minimum anymore
+	for q.size > 0 && q.values[q.index(q.size-1)] >= value {
+		q.size--
+	}
+	i := q.index(q.size)
+	q.pos[i], q.values[i] = pos, value
+	q.size++
+}
+
+// expire drops the values pushed before the given position
+func (q *minQueue) expire(before int) {
+	for q.size > 0 && q.pos[q.head] < before {
+		q.head = (q.head + 1) % len(q.pos)
+		q.size--
+	}
+}
+
+func (q *minQueue) min() float64 {
+	return q.values[q.head]
+}
+
+func (q *minQueue) index(i int) int {
+	return (q.head + i) % len(q.pos)
+}
@@ -0,0 +1,41 @@
+package master_bus
+
+import (
+	"github.com/faiface/beep"
+	"sync"
+	"time"
+)
+
+const (
+	// How far ahead the limiter looks for peaks. The mix is delayed by this duration
+	lookAhead = 5 * time.Millisecond
+	// Duration of the fade applied when muting or unmuting, so that it doesn't click
+	muteFade = 10 * time.Millisecond
+)
+
+// DefaultLimiter keeps the mix 1dB below full scale
+var DefaultLimiter = LimiterOpt{Enabled: true, CeilingDb: -1, Release: 100 * time.Millisecond}
+
+// Bus is the last stage of the mix, applied to the sum of the tracks: master gain, mute, and a look-ahead peak limiter
+// preventing the mix from clipping
+type Bus struct {
+	src        beep.Streamer
+	sampleRate beep.SampleRate
+	mu         sync.Mutex
+	// Master gain, in decibels
+	gain *ramp
+	// Linear gain moving between 1 and 0 when muting and unmuting
+	mute  *ramp
+	muted bool
+	// Settings of the limiter, the limiter itself always runs so that the delay of the mix stays constant
+	limiterOpt LimiterOpt
+	limiter    *limiter
+}
+
+type LimiterOpt struct {
+	Enabled bool
+	// Maximum level of the mix, in decibels relative to full scale
+	CeilingDb float64
+	// Time it takes for the limiter to stop reducing the gain once a peak is over
+	Release time.Duration
+}
@@ -0,0 +1,137 @@
+package master_bus
+
+import (
+	"github.com/faiface/beep"
+	"math"
+	"time"
+)
+
+// NewBus creates a master bus processing the mix produced by src, with a unity gain and the default limiter
+func NewBus(src beep.Streamer, sampleRate beep.SampleRate) *Bus {
+	b := &Bus{
+		src:        src,
+		sampleRate: sampleRate,
+		gain:       &ramp{},
+		mute:       &ramp{value: 1, to: 1},
+		limiter:    newLimiter(sampleRate.N(lookAhead)),
+	}
+	b.setLimiter(DefaultLimiter)
+	return b
+}
+
+// Latency returns the number of samples the mix is delayed by
+func (b *Bus) Latency() int {
+	return b.limiter.lookAhead
+}
+
+// SetGain moves the master gain to targetDb over the given duration. A zero duration applies it immediately
+func (b *Bus) SetGain(targetDb float64, duration time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.gain.rampTo(targetDb, b.sampleRate.N(duration))
+}
+
+// Gain returns the master gain in decibels. If a ramp is in progress, this is the level the ramp is going to
+func (b *Bus) Gain() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.gain.to
+}
+
+// SetMuted silences the whole mix, or makes it heard again. The change is faded to avoid clicks
+func (b *Bus) SetMuted(muted bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.muted = muted
+	target := 1.0
+	if muted {
+		target = 0
+	}
+	b.mute.rampTo(target, b.sampleRate.N(muteFade))
+}
+
+// Muted returns whether the mix is muted
+func (b *Bus) Muted() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.muted
+}
+
+// SetLimiter changes the settings of the limiter
+func (b *Bus) SetLimiter(opt LimiterOpt) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.setLimiter(opt)
+}
+
+func (b *Bus) setLimiter(opt LimiterOpt) {
+	b.limiterOpt = opt
+	b.limiter.ceiling = math.Pow(10, opt.CeilingDb/20)
+	if !opt.Enabled {
+		b.limiter.ceiling = math.Inf(1)
+	}
+	b.limiter.release = 1
+	if n := b.sampleRate.N(opt.Release); n > 0 {
+		b.limiter.release = 1 - math.Exp(-1/float64(n))
+	}
+}
+
+// Limiter returns the settings of the limiter
+func (b *Bus) Limiter() LimiterOpt {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limiterOpt
+}
+
+// Stream implements beep.Streamer. The mix goes on whatever the source does, missing samples are replaced by silence
+func (b *Bus) Stream(samples [][2]float64) (n int, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n, ok = b.src.Stream(samples)
+	if !ok {
+		n = 0
+	}
+	for i := n; i < len(samples); i++ {
+		samples[i] = [2]float64{}
+	}
+	for i := range samples {
+		gain := math.Pow(10, b.gain.next()/20) * b.mute.next()
+		samples[i] = b.limiter.process([2]float64{samples[i][0] * gain, samples[i][1] * gain})
+	}
+	return len(samples), true
+}
+
+func (b *Bus) Err() error {
+	return nil
+}
+
+// ramp moves a value linearly to a target over a number of samples
+type ramp struct {
+	value, to float64
+	// Change of the value at each sample, and number of samples left in the ramp
+	step float64
+	left int
+}
+
+func (r *ramp) rampTo(to float64, length int) {
+	r.to = to
+	if length <= 0 {
+		r.value, r.left = to, 0
+		return
+	}
+	r.step = (to - r.value) / float64(length)
+	r.left = length
+}
+
+// next moves the ramp forward by a sample, and returns the value for this sample.
+// The target is reached on the last sample of the ramp
+func (r *ramp) next() float64 {
+	if r.left > 0 {
+		r.left--
+		r.value += r.step
+		if r.left == 0 {
+			r.value = r.to
+		}
+	}
+	return r.value
+}
@@ -0,0 +1,102 @@
+package master_bus
+
+import (
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	test_utils "live-audio-mixer/test-utils"
+	"math"
+	"testing"
+	"time"
+)
+
+const rate = beep.SampleRate(48000)
+
+func TestBus_Latency(t *testing.T) {
+	b := NewBus(test_utils.NewConstant(0.5), rate)
+	assert.Equal(t, rate.N(lookAhead), b.Latency())
+	out := test_utils.Pull(b, 1000)
+	assert.Equal(t, 0.0, out[b.Latency()-1][0])
+	// Below the ceiling, the mix is left untouched
+	for _, sample := range out[b.Latency():] {
+		assert.Equal(t, [2]float64{0.5, 0.5}, sample)
+	}
+}
+
+func TestBus_Limiter(t *testing.T) {
+	src := &test_utils.Sine{Amplitude: 0.5, Frequency: 480, SampleRate: rate, OutOfPhase: true}
+	b := NewBus(src, rate)
+	test_utils.Pull(b, 4800)
+	ceiling := math.Pow(10, DefaultLimiter.CeilingDb/20)
+	// A sudden loud passage never exceeds the ceiling
+	src.Amplitude = 3
+	out := test_utils.Pull(b, 4800)
+	peak := 0.0
+	for _, sample := range out {
+		peak = math.Max(peak, math.Max(math.Abs(sample[0]), math.Abs(sample[1])))
+	}
+	assert.LessOrEqual(t, peak, ceiling)
+	assert.Greater(t, peak, ceiling*0.9)
+
+	// The gain recovers once the loud passage is over
+	src.Amplitude = 0.5
+	test_utils.Pull(b, rate.N(time.Second))
+	out = test_utils.Pull(b, 4800)
+	peak = 0
+	for _, sample := range out {
+		peak = math.Max(peak, math.Abs(sample[0]))
+	}
+	assert.InDelta(t, 0.5, peak, 0.001)
+
+	// Once disabled, nothing is limited anymore
+	b.SetLimiter(LimiterOpt{})
+	src.Amplitude = 3
+	out = test_utils.Pull(b, 4800)
+	assert.InDelta(t, 3, maxAbs(out[b.Latency():]), 0.01)
+}
+
+func TestBus_Gain(t *testing.T) {
+	b := NewBus(test_utils.NewConstant(0.5), rate)
+	b.SetGain(-6, 0)
+	assert.Equal(t, -6.0, b.Gain())
+	out := test_utils.Pull(b, 1000)
+	assert.InDelta(t, 0.5*math.Pow(10, -6.0/20), out[999][0], 1e-9)
+
+	// Ramps reach their target at the end of the duration, delayed by the look-ahead
+	b.SetGain(0, 10*time.Millisecond)
+	out = test_utils.Pull(b, rate.N(10*time.Millisecond)+b.Latency())
+	assert.Less(t, out[b.Latency()+rate.N(5*time.Millisecond)][0], 0.5)
+	assert.InDelta(t, 0.5, out[len(out)-1][0], 1e-9)
+}
+
+func TestBus_Mute(t *testing.T) {
+	b := NewBus(test_utils.NewConstant(0.5), rate)
+	test_utils.Pull(b, 1000)
+	b.SetMuted(true)
+	assert.True(t, b.Muted())
+	out := test_utils.Pull(b, rate.N(muteFade)+b.Latency())
+	// The mute is faded
+	assert.Greater(t, out[b.Latency()+1][0], 0.0)
+	assert.Equal(t, 0.0, out[len(out)-1][0])
+	b.SetMuted(false)
+	test_utils.Pull(b, rate.N(muteFade)+b.Latency())
+	assert.Equal(t, 0.5, test_utils.Pull(b, 1)[0][0])
+}
+
+// The mix goes on with silence once the source is drained
+func TestBus_DrainedSource(t *testing.T) {
+	b := NewBus(beep.Take(100, test_utils.NewConstant(0.5)), rate)
+	samples := make([][2]float64, 1000)
+	n, ok := b.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 1000, n)
+	assert.Equal(t, 0.5, samples[b.Latency()+99][0])
+	assert.Equal(t, 0.0, samples[b.Latency()+100][0])
+}
+
+func maxAbs(samples [][2]float64) float64 {
+	peak := 0.0
+	for _, sample := range samples {
+		peak = math.Max(peak, math.Max(math.Abs(sample[0]), math.Abs(sample[1])))
+	}
+	return peak
+}
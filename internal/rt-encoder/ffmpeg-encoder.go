@@ -7,10 +7,27 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"time"
 )
 
+// Duration of the samples written to ffmpeg at once. Reading from the mix blocks until a whole chunk is available
+const chunkDuration = 20 * time.Millisecond
+
+// FFEncode encodes a stream using the default profile, Opus in an Ogg container
 func FFEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) (err error) {
+	return ffEncode(w, s, format, signalCh, DefaultProfile)
+}
+
+// NewFFEncoder returns an encoding function using the given profile.
+// The profile must have been validated beforehand
+func NewFFEncoder(profile Profile) func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+	return func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		return ffEncode(w, s, format, signalCh, profile)
+	}
+}
+
+func ffEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal, profile Profile) (err error) {
 	defer func() {
 		if err != nil {
 			err = errors.Wrap(err, "wav")
@@ -24,7 +41,10 @@ func FFEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh ch
 		return errors.New("wav: unsupported precision, 1, 2 or 3 is supported")
 	}
 	pipeReader, pipeWriter := io.Pipe()
-	cmd := exec.Command("ffmpeg", "-re", "-f", "s16le", "-ar", "48000", "-ac", "2", "-i", "pipe:0", "-c:a", "libopus", "-f", "ogg", "pipe:1")
+	// The stream is already paced by the recorder, ffmpeg must not throttle its input
+	args := []string{"-f", rawFormat(format), "-ar", strconv.Itoa(int(format.SampleRate)), "-ac", strconv.Itoa(format.NumChannels), "-i", "pipe:0"}
+	args = append(args, profile.outputArgs(format)...)
+	cmd := exec.Command("ffmpeg", append(args, "pipe:1")...)
 	cmd.Stdin = pipeReader
 	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
@@ -32,8 +52,7 @@ func FFEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh ch
 		return err
 	}
 
-	recordingDuration := 1 * time.Second // Recording duration of one second.
-	chunkSize := int(48000) * 1 * int(recordingDuration.Seconds())
+	chunkSize := format.SampleRate.N(chunkDuration)
 	var (
 		bw      = pipeWriter
 		samples = make([][2]float64, chunkSize)
@@ -44,16 +63,12 @@ Loop:
 	for {
 		select {
 		case <-signalCh:
-			// Can't use signals, as they are not compatible on both Windows and Linux
-			err = cmd.Process.Kill()
-			if err != nil {
-				return err
-			}
 			break Loop
 		default:
 			n, ok := s.Stream(samples)
 			if !ok {
-				break
+				// The stream is over, no more samples will be produced
+				break Loop
 			}
 			buf := buffer
 			switch {
@@ -75,5 +90,23 @@ Loop:
 			written += nn
 		}
 	}
-	return nil
+	// Can't use signals, as they are not compatible on both Windows and Linux
+	// Closing ffmpeg input lets it flush the remaining data and end the file properly
+	err = pipeWriter.Close()
+	if err != nil {
+		return err
+	}
+	return cmd.Wait()
+}
+
+// rawFormat returns the ffmpeg raw PCM format matching the way samples are encoded
+func rawFormat(format beep.Format) string {
+	switch format.Precision {
+	case 1:
+		return "u8"
+	case 3:
+		return "s24le"
+	default:
+		return "s16le"
+	}
 }
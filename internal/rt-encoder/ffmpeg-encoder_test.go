@@ -7,11 +7,9 @@ import (
 	"log"
 	"os"
 	"os/exec"
-	"os/signal"
 	"path"
 	"strconv"
 	"strings"
-	"syscall"
 	"testing"
 	"time"
 )
@@ -84,18 +82,9 @@ func Test_Static(t *testing.T) {
 
 	// As the mixed stream emits silence when no stream are playing
 	// the stream will not stop on its own
-	// So we way for some time before stopping it manually
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT)
-	go func(stop chan os.Signal) {
-		for {
-			select {
-			case <-time.After(RecordingDurationSecs * time.Second):
-				signalChan <- syscall.SIGINT
-			}
-		}
-	}(signalChan)
-	err := FFEncode(as.Target, &mixer, mixFormat, signalChan)
+	// The encoder doesn't pace its input, so the recording duration is bounded by the number of samples
+	recording := beep.Take(mixFormat.SampleRate.N(RecordingDurationSecs*time.Second), &mixer)
+	err := FFEncode(as.Target, recording, mixFormat, make(chan os.Signal, 1))
 	assert.NoError(t, err)
 	err = as.Target.Close()
 	assert.NoError(t, err)
@@ -118,35 +107,22 @@ func Test_DynamicSwitch(t *testing.T) {
 
 	// And write them to a file
 	mixFormat := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
-	// As the mixed stream emits silence when no stream are playing
-	// the stream will not stop on its own
-	// So we way for some time before stopping it manually
-	signalChan := make(chan os.Signal, 1)
-	signal.Notify(signalChan, os.Interrupt, syscall.SIGINT)
-	go func() {
-		for {
-			select {
-			case <-time.After(RecordingDurationSecs / 2 * time.Second):
-				err := as.Bg.Close()
-				if err != nil {
-					log.Fatal(err)
-				}
-				// Chicken will appear slowed down. This is normal, as the sample rate is 48k, but the mixer is 48K
-				// This isn't what we're testing for here
-				mixer.Add(as.Chicken)
-				return
-			}
-		}
-	}()
-	go func(stop chan os.Signal) {
-		for {
-			select {
-			case <-time.After(RecordingDurationSecs * time.Second):
-				signalChan <- syscall.SIGINT
+	// Switch halfway through the recording
+	half := mixFormat.SampleRate.N(RecordingDurationSecs * time.Second / 2)
+	recording := beep.Seq(
+		beep.Take(half, &mixer),
+		beep.Callback(func() {
+			err := as.Bg.Close()
+			if err != nil {
+				log.Fatal(err)
 			}
-		}
-	}(signalChan)
-	err := FFEncode(as.Target, &mixer, mixFormat, signalChan)
+			// Chicken will appear slowed down. This is normal, as the sample rate is 48k, but the mixer is 48K
+			// This isn't what we're testing for here
+			mixer.Add(as.Chicken)
+		}),
+		beep.Take(half, &mixer),
+	)
+	err := FFEncode(as.Target, recording, mixFormat, make(chan os.Signal, 1))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,247 @@
+package rt_encoder
+
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/wav"
+	"github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/mewkiz/flac/meta"
+	"github.com/pkg/errors"
+	"io"
+	"math"
+	"os"
+)
+
+const (
+	// Number of samples per channel in each FLAC frame, but the last one which may be shorter
+	flacBlockSize = 4000
+	// Minimum number of samples per channel in a FLAC frame
+	flacMinBlockSize = 16
+	// Highest order of the fixed predictors of FLAC
+	flacMaxFixedOrder = 4
+	// Highest Rice parameter, the next one marks unencoded residuals. Parameters above 14 take 5 bits instead of 4
+	flacMaxRiceParam = 30
+)
+
+// WavEncode writes the stream as a WAV file in-process, without spawning ffmpeg.
+// As the WAV header holds the size of the data, the destination must be seekable
+func WavEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+	return wav.Encode(w, newStoppable(s, signalCh), format)
+}
+
+// FlacEncode writes the stream as a FLAC file in-process, without spawning ffmpeg.
+// Each channel of each frame is compressed with the fixed predictor of FLAC leaving the smallest residuals,
+// or stored as is when it doesn't compress. When the destination is seekable,
+// the stream info (duration, checksum) is updated once the recording is over
+func FlacEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) (err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Wrap(err, "flac")
+		}
+	}()
+	if format.NumChannels != 1 && format.NumChannels != 2 {
+		return errors.New("flac: unsupported number of channels, 1 or 2 is supported")
+	}
+	if format.Precision != 1 && format.Precision != 2 && format.Precision != 3 {
+		return errors.New("flac: unsupported precision, 1, 2 or 3 is supported")
+	}
+	info := &meta.StreamInfo{
+		BlockSizeMin:  flacBlockSize,
+		BlockSizeMax:  flacBlockSize,
+		SampleRate:    uint32(format.SampleRate),
+		NChannels:     uint8(format.NumChannels),
+		BitsPerSample: uint8(format.Precision * 8),
+	}
+	// The encoder closes its writer when it is a closer, closing the destination is left to the caller
+	enc, err := flac.NewEncoder(struct{ io.WriteSeeker }{w}, info)
+	if err != nil {
+		return err
+	}
+
+	s = newStoppable(s, signalCh)
+	var (
+		samples = make([][2]float64, flacBlockSize)
+		// Samples buffered until a full frame can be written
+		filled int
+		// Full frames written so far
+		frames int
+	)
+	for {
+		n, ok := s.Stream(samples[filled:])
+		filled += n
+		if !ok {
+			break
+		}
+		if filled == len(samples) {
+			if err := enc.WriteFrame(flacFrame(samples, format)); err != nil {
+				return err
+			}
+			frames++
+			filled = 0
+		}
+	}
+	if filled > 0 {
+		// The last frame can be shorter, but not below the minimum size, pad it with silence
+		for ; filled < flacMinBlockSize; filled++ {
+			samples[filled] = [2]float64{}
+		}
+		if err := enc.WriteFrame(flacFrame(samples[:filled], format)); err != nil {
+			return err
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	if frames == 0 {
+		return nil
+	}
+	return fixBlockSizeMin(w, flacBlockSize)
+}
+
+// fixBlockSizeMin sets the minimum block size of the stream info, once the encoder updated it.
+// The encoder takes the shorter last frame into account, which the minimum block size must exclude
+// in a stream of fixed block size
+func fixBlockSizeMin(w io.WriteSeeker, blockSize uint16) error {
+	// The block size follows the signature and the header of the stream info block
+	if _, err := w.Seek(8, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := w.Write([]byte{byte(blockSize >> 8), byte(blockSize)}); err != nil {
+		return err
+	}
+	_, err := w.Seek(0, io.SeekEnd)
+	return err
+}
+
+// flacFrame quantizes samples into a FLAC frame.
+func flacFrame(samples [][2]float64, format beep.Format) *frame.Frame {
+	bps := format.Precision * 8
+	channels := frame.ChannelsLR
+	if format.NumChannels == 1 {
+		channels = frame.ChannelsMono
+	}
+	f := &frame.Frame{
+		Header: frame.Header{
+			HasFixedBlockSize: true,
+			BlockSize:         uint16(len(samples)),
+			SampleRate:        uint32(format.SampleRate),
+			Channels:          channels,
+			BitsPerSample:     uint8(bps),
+		},
+		Subframes: make([]*frame.Subframe, format.NumChannels),
+	}
+	for c := range f.Subframes {
+		data := make([]int32, len(samples))
+		for i, sample := range samples {
+			v := sample[c]
+			if format.NumChannels == 1 {
+				v = (sample[0] + sample[1]) / 2
+			}
+			data[i] = quantize(v, bps)
+		}
+		f.Subframes[c] = flacSubframe(data, bps)
+	}
+	return f
+}
+
+// flacSubframe stores the samples of a channel in the fewest bits: as a constant, with a fixed predictor, or as is.
+// A fixed predictor of order n predicts each sample from the n previous ones, the residuals being Rice coded
+func flacSubframe(data []int32, bps int) *frame.Subframe {
+	subframe := &frame.Subframe{Samples: data, NSamples: len(data)}
+	constant := true
+	for _, v := range data {
+		constant = constant && v == data[0]
+	}
+	if constant {
+		subframe.Pred = frame.PredConstant
+		return subframe
+	}
+	subframe.Pred = frame.PredVerbatim
+	size := len(data) * bps
+	// The residuals of each order are the differences between the successive residuals of the previous order
+	residuals := make([]int64, len(data))
+	for i, v := range data {
+		residuals[i] = int64(v)
+	}
+	for order := 0; order <= flacMaxFixedOrder; order++ {
+		if order > 0 {
+			for i := len(residuals) - 1; i >= order; i-- {
+				residuals[i] -= residuals[i-1]
+			}
+		}
+		param, riceSize := riceParam(residuals[order:])
+		method, paramSize := frame.ResidualCodingMethodRice1, 4
+		if param > 14 {
+			method, paramSize = frame.ResidualCodingMethodRice2, 5
+		}
+		// Warm-up samples, coding method, partition order and Rice parameter, then the residuals
+		if fixedSize := order*bps + 2 + 4 + paramSize + riceSize; fixedSize < size {
+			size = fixedSize
+			subframe.SubHeader = frame.SubHeader{
+				Pred:                 frame.PredFixed,
+				Order:                order,
+				ResidualCodingMethod: method,
+				RiceSubframe:         &frame.RiceSubframe{Partitions: []frame.RicePartition{{Param: param}}},
+			}
+		}
+	}
+	return subframe
+}
+
+// riceParam returns the Rice parameter coding residuals in the fewest bits, along with this number of bits
+func riceParam(residuals []int64) (param uint, size int) {
+	size = math.MaxInt
+	for k := uint(0); k <= flacMaxRiceParam; k++ {
+		// Each residual is folded to a positive integer, whose k low bits are stored as is,
+		// and the others in unary followed by a stop bit
+		kSize := len(residuals) * int(k+1)
+		for _, r := range residuals {
+			kSize += int(uint64(r<<1^r>>63) >> k)
+		}
+		// The size only decreases until the best parameter
+		if kSize >= size {
+			break
+		}
+		param, size = k, kSize
+	}
+	return param, size
+}
+
+// quantize converts a sample in the [-1, 1] range to a signed integer of bps bits
+func quantize(v float64, bps int) int32 {
+	if v < -1 {
+		v = -1
+	}
+	if v > 1 {
+		v = 1
+	}
+	return int32(v * float64(int32(1)<<(bps-1)-1))
+}
+
+// stoppable ends the wrapped stream once a signal has been received
+type stoppable struct {
+	s        beep.Streamer
+	signalCh chan os.Signal
+	stopped  bool
+}
+
+func newStoppable(s beep.Streamer, signalCh chan os.Signal) *stoppable {
+	return &stoppable{s: s, signalCh: signalCh}
+}
+
+func (st *stoppable) Stream(samples [][2]float64) (n int, ok bool) {
+	if st.stopped {
+		return 0, false
+	}
+	select {
+	case <-st.signalCh:
+		st.stopped = true
+		return 0, false
+	default:
+		return st.s.Stream(samples)
+	}
+}
+
+func (st *stoppable) Err() error {
+	return st.s.Err()
+}
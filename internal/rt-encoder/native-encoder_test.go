@@ -0,0 +1,187 @@
+package rt_encoder
+
+import (
+	"github.com/faiface/beep"
+	"github.com/faiface/beep/flac"
+	"github.com/faiface/beep/wav"
+	mewkiz_flac "github.com/mewkiz/flac"
+	"github.com/mewkiz/flac/frame"
+	"github.com/stretchr/testify/assert"
+	"io"
+	test_utils "live-audio-mixer/test-utils"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// Encodes 0.2s of a constant signal followed by 0.3s of silence, and returns the encoded file
+func nativeEncode(t *testing.T, encode func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error, format beep.Format) *os.File {
+	dst, err := os.Create(path.Join(t.TempDir(), "mix"))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = dst.Close() })
+
+	src := beep.Seq(
+		beep.Take(format.SampleRate.N(200*time.Millisecond), &test_utils.Constant{Sample: [2]float64{0.5, -0.25}}),
+		beep.Take(format.SampleRate.N(300*time.Millisecond), &test_utils.Constant{}),
+	)
+	assert.NoError(t, encode(dst, src, format, make(chan os.Signal, 1)))
+	_, err = dst.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	return dst
+}
+
+func TestWavEncode(t *testing.T) {
+	format := beep.Format{SampleRate: 44100, NumChannels: 1, Precision: 3}
+	dst := nativeEncode(t, WavEncode, format)
+	decoded, decodedFormat, err := wav.Decode(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, format, decodedFormat)
+	assert.Equal(t, format.SampleRate.N(500*time.Millisecond), decoded.Len())
+
+	// The wav decoder doesn't scale samples back properly, read the first one from the data chunk directly.
+	// Channels are averaged in mono : (0.5 - 0.25) / 2 * (2^23 - 1)
+	first := make([]byte, 3)
+	_, err = dst.ReadAt(first, 44)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xff, 0xff, 0x0f}, first)
+}
+
+func TestFlacEncode(t *testing.T) {
+	for _, format := range []beep.Format{
+		{SampleRate: 48000, NumChannels: 2, Precision: 2},
+		{SampleRate: 44100, NumChannels: 1, Precision: 3},
+	} {
+		dst := nativeEncode(t, FlacEncode, format)
+		decoded, decodedFormat, err := flac.Decode(dst)
+		assert.NoError(t, err)
+		assert.Equal(t, format, decodedFormat)
+		assert.Equal(t, format.SampleRate.N(500*time.Millisecond), decoded.Len())
+
+		samples := make([][2]float64, 1)
+		_, ok := decoded.Stream(samples)
+		assert.True(t, ok)
+		expected := [2]float64{0.5, -0.25}
+		if format.NumChannels == 1 {
+			expected = [2]float64{0.125, 0.125}
+		}
+		assert.InDelta(t, expected[0], samples[0][0], 0.001)
+		assert.InDelta(t, expected[1], samples[0][1], 0.001)
+	}
+}
+
+// A stereo 440Hz sine, out of phase between the channels
+func sine() *test_utils.Sine {
+	return &test_utils.Sine{Amplitude: 0.5, Frequency: 440, SampleRate: 48000, OutOfPhase: true}
+}
+
+// Frames are compressed without loss
+func TestFlacEncode_Compression(t *testing.T) {
+	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+	n := format.SampleRate.N(time.Second)
+	dst, err := os.Create(path.Join(t.TempDir(), "mix"))
+	assert.NoError(t, err)
+	defer dst.Close()
+	assert.NoError(t, FlacEncode(dst, beep.Take(n, sine()), format, make(chan os.Signal)))
+	info, err := dst.Stat()
+	assert.NoError(t, err)
+	assert.Less(t, info.Size(), int64(n*format.Width()/2))
+
+	_, err = dst.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	decoded, _, err := flac.Decode(dst)
+	assert.NoError(t, err)
+	assert.Equal(t, n, decoded.Len())
+	expected, actual := make([][2]float64, n), make([][2]float64, n)
+	sine().Stream(expected)
+	read := 0
+	for read < n {
+		sn, ok := decoded.Stream(actual[read:])
+		if !ok {
+			break
+		}
+		read += sn
+	}
+	assert.Equal(t, n, read)
+	for i := range expected {
+		for c := range expected[i] {
+			if quantize(expected[i][c], 16) != int32(math.Round(actual[i][c]*(1<<15))) {
+				assert.Failf(t, "sample mismatch", "sample %d of channel %d: expected %v, got %v", i, c, expected[i][c], actual[i][c])
+				return
+			}
+		}
+	}
+}
+
+// Each channel is stored with the predictor taking the fewest bits
+func TestFlacSubframe(t *testing.T) {
+	ramp, noise := make([]int32, 64), make([]int32, 64)
+	random := rand.New(rand.NewSource(1))
+	for i := range ramp {
+		ramp[i] = int32(100 * i)
+		noise[i] = random.Int31n(1<<16) - 1<<15
+	}
+	subframe := flacSubframe(ramp, 16)
+	assert.Equal(t, frame.PredFixed, subframe.Pred)
+	// From the second order on, the residuals of a ramp are null
+	assert.Equal(t, 2, subframe.Order)
+	assert.Equal(t, uint(0), subframe.RiceSubframe.Partitions[0].Param)
+
+	assert.Equal(t, frame.PredVerbatim, flacSubframe(noise, 16).Pred)
+	assert.Equal(t, frame.PredConstant, flacSubframe(make([]int32, 64), 16).Pred)
+}
+
+// The last frame may be of any size, the stream info keeps the fixed block size of the other frames
+func TestFlacEncode_BlockSizes(t *testing.T) {
+	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+	// Number of samples streamed, number of samples expected in the file, and minimum block size of the stream
+	cases := [][3]int{
+		{flacBlockSize + 2304, flacBlockSize + 2304, flacBlockSize},
+		{flacBlockSize * 2, flacBlockSize * 2, flacBlockSize},
+		{1024, 1024, 1024},
+		// The last frame is padded up to the minimum block size
+		{flacBlockSize + 10, flacBlockSize + flacMinBlockSize, flacBlockSize},
+	}
+	for _, c := range cases {
+		dst, err := os.Create(path.Join(t.TempDir(), "mix"))
+		assert.NoError(t, err)
+		assert.NoError(t, FlacEncode(dst, beep.Take(c[0], &test_utils.Constant{Sample: [2]float64{0.5, -0.25}}), format, make(chan os.Signal)))
+		_, err = dst.Seek(0, io.SeekStart)
+		assert.NoError(t, err)
+		stream, err := mewkiz_flac.New(dst)
+		assert.NoError(t, err)
+		assert.Equal(t, uint16(c[2]), stream.Info.BlockSizeMin)
+		assert.Equal(t, uint16(min(c[1], flacBlockSize)), stream.Info.BlockSizeMax)
+		assert.Equal(t, uint64(c[1]), stream.Info.NSamples)
+		_, err = dst.Seek(0, io.SeekStart)
+		assert.NoError(t, err)
+		decoded, _, err := flac.Decode(dst)
+		assert.NoError(t, err)
+		assert.Equal(t, c[1], decoded.Len())
+		read := 0
+		for {
+			sn, ok := decoded.Stream(make([][2]float64, 512))
+			if !ok {
+				break
+			}
+			read += sn
+		}
+		assert.Equal(t, decoded.Len(), read)
+		assert.NoError(t, dst.Close())
+	}
+}
+
+// An endless stream is encoded until the stop signal
+func TestNativeEncode_Stop(t *testing.T) {
+	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+	for _, encode := range []func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error{WavEncode, FlacEncode} {
+		dst, err := os.Create(path.Join(t.TempDir(), "mix"))
+		assert.NoError(t, err)
+		signalCh := make(chan os.Signal, 1)
+		signalCh <- os.Interrupt
+		assert.NoError(t, encode(dst, &test_utils.Constant{}, format, signalCh))
+		assert.NoError(t, dst.Close())
+	}
+}
@@ -0,0 +1,134 @@
+package rt_encoder
+
+import (
+	"fmt"
+	"github.com/faiface/beep"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+)
+
+type Codec string
+
+const (
+	Opus Codec = "opus"
+	Mp3  Codec = "mp3"
+	Aac  Codec = "aac"
+	Flac Codec = "flac"
+	Wav  Codec = "wav"
+)
+
+// Profile describes how a recording is encoded
+type Profile struct {
+	Codec Codec
+	// Target bitrate in kbps. Ignored by lossless codecs, 0 uses the encoder default
+	BitrateKbps int
+	// ffmpeg muxer to use. When empty, the default container of the codec is used
+	Container string
+	// Encode in-process instead of spawning ffmpeg. Only available for WAV and FLAC in their own container
+	InProcess bool
+}
+
+// DefaultProfile is the profile used when none is specified: Opus in an Ogg container
+var DefaultProfile = Profile{Codec: Opus, Container: "ogg"}
+
+type codecInfo struct {
+	// ffmpeg encoder name
+	encoder  string
+	lossless bool
+	// Encoding function used when encoding in-process, nil if the codec requires ffmpeg
+	native func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error
+	// Containers able to hold the codec while being written to a pipe, the first one being the default
+	containers []string
+}
+
+var codecs = map[Codec]codecInfo{
+	Opus: {encoder: "libopus", containers: []string{"ogg", "webm", "matroska"}},
+	Mp3:  {encoder: "libmp3lame", containers: []string{"mp3", "matroska"}},
+	// MP4 requires seeking back into the file once done, ADTS is the streamable alternative
+	Aac:  {encoder: "aac", containers: []string{"adts", "matroska"}},
+	Flac: {encoder: "flac", lossless: true, native: FlacEncode, containers: []string{"flac", "ogg", "matroska"}},
+	// The actual PCM encoder depends on the precision of the mix, see pcmEncoder
+	Wav: {lossless: true, native: WavEncode, containers: []string{"wav"}},
+}
+
+// File extension for each container
+var extensions = map[string]string{
+	"ogg":      "ogg",
+	"webm":     "webm",
+	"matroska": "mka",
+	"mp3":      "mp3",
+	"adts":     "aac",
+	"flac":     "flac",
+	"wav":      "wav",
+}
+
+// Validate checks that the codec is known and can be written in the chosen container.
+// An empty container is replaced by the codec default one
+func (p Profile) Validate() (Profile, error) {
+	info, ok := codecs[p.Codec]
+	if !ok {
+		return p, fmt.Errorf(`unsupported codec "%s"`, p.Codec)
+	}
+	if p.Container == "" {
+		p.Container = info.containers[0]
+	}
+	if !slices.Contains(info.containers, p.Container) {
+		return p, fmt.Errorf(`codec "%s" can't be written in container "%s", valid containers are %v`, p.Codec, p.Container, info.containers)
+	}
+	if p.BitrateKbps < 0 {
+		return p, fmt.Errorf("invalid bitrate %d kbps", p.BitrateKbps)
+	}
+	if p.InProcess && (info.native == nil || p.Container != info.containers[0]) {
+		return p, fmt.Errorf(`codec "%s" in container "%s" can't be encoded in-process, only wav and flac in their own container can`, p.Codec, p.Container)
+	}
+	return p, nil
+}
+
+// NewEncoder returns the encoding function matching the profile, either in-process or using ffmpeg.
+// The profile must have been validated beforehand
+func NewEncoder(profile Profile) func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+	if profile.InProcess {
+		return codecs[profile.Codec].native
+	}
+	return NewFFEncoder(profile)
+}
+
+// Extension returns the file extension matching the container of the profile, without the dot
+func (p Profile) Extension() string {
+	if p.Container == "" {
+		return extensions[codecs[p.Codec].containers[0]]
+	}
+	return extensions[p.Container]
+}
+
+// outputArgs returns the ffmpeg output arguments for this profile
+func (p Profile) outputArgs(format beep.Format) []string {
+	info := codecs[p.Codec]
+	encoder := info.encoder
+	if p.Codec == Wav {
+		encoder = pcmEncoder(format)
+	}
+	args := []string{"-c:a", encoder}
+	if p.BitrateKbps > 0 && !info.lossless {
+		args = append(args, "-b:a", strconv.Itoa(p.BitrateKbps)+"k")
+	}
+	container := p.Container
+	if container == "" {
+		container = info.containers[0]
+	}
+	return append(args, "-f", container)
+}
+
+// pcmEncoder returns the ffmpeg PCM encoder matching the precision of the format
+func pcmEncoder(format beep.Format) string {
+	switch format.Precision {
+	case 1:
+		return "pcm_u8"
+	case 3:
+		return "pcm_s24le"
+	default:
+		return "pcm_s16le"
+	}
+}
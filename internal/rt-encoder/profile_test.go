@@ -0,0 +1,47 @@
+package rt_encoder
+
+import (
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProfile_Validate(t *testing.T) {
+	p, err := Profile{Codec: Mp3, BitrateKbps: 192}.Validate()
+	assert.NoError(t, err)
+	assert.Equal(t, "mp3", p.Container)
+	assert.Equal(t, "mp3", p.Extension())
+
+	p, err = Profile{Codec: Aac}.Validate()
+	assert.NoError(t, err)
+	assert.Equal(t, "aac", p.Extension())
+
+	p, err = Profile{Codec: Flac, Container: "ogg"}.Validate()
+	assert.NoError(t, err)
+	assert.Equal(t, "ogg", p.Extension())
+
+	_, err = Profile{Codec: Wav, Container: "ogg"}.Validate()
+	assert.Error(t, err)
+	_, err = Profile{Codec: "vorbis"}.Validate()
+	assert.Error(t, err)
+	_, err = Profile{Codec: Opus, BitrateKbps: -1}.Validate()
+	assert.Error(t, err)
+
+	// Only WAV and FLAC in their own container can be encoded without ffmpeg
+	_, err = Profile{Codec: Flac, InProcess: true}.Validate()
+	assert.NoError(t, err)
+	_, err = Profile{Codec: Flac, Container: "ogg", InProcess: true}.Validate()
+	assert.Error(t, err)
+	_, err = Profile{Codec: Opus, InProcess: true}.Validate()
+	assert.Error(t, err)
+}
+
+func TestProfile_OutputArgs(t *testing.T) {
+	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+	assert.Equal(t, []string{"-c:a", "libopus", "-f", "ogg"}, DefaultProfile.outputArgs(format))
+	assert.Equal(t, []string{"-c:a", "libmp3lame", "-b:a", "320k", "-f", "mp3"}, Profile{Codec: Mp3, BitrateKbps: 320}.outputArgs(format))
+	// Bitrate is meaningless for lossless codecs
+	assert.Equal(t, []string{"-c:a", "flac", "-f", "flac"}, Profile{Codec: Flac, BitrateKbps: 320}.outputArgs(format))
+	format.Precision = 3
+	assert.Equal(t, []string{"-c:a", "pcm_s24le", "-f", "wav"}, Profile{Codec: Wav}.outputArgs(format))
+}
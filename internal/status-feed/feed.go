@@ -0,0 +1,74 @@
+package status_feed
+
+import (
+	pb "live-audio-mixer/proto"
+	"log/slog"
+	"sync"
+)
+
+// Number of notifications a watcher can lag behind before being dropped
+const watcherBacklog = 256
+
+// Feed forwards the notifications about a record to any number of watchers
+type Feed struct {
+	mu       sync.Mutex
+	watchers map[chan *pb.Status]struct{}
+	closed   bool
+}
+
+func NewFeed() *Feed {
+	return &Feed{
+		watchers: map[chan *pb.Status]struct{}{},
+	}
+}
+
+// Subscribe registers a new watcher. The returned channel receives every notification published from now on,
+// and is closed when the feed ends or when the watcher is too slow to keep up.
+// The returned function must be called to unsubscribe
+func (f *Feed) Subscribe() (<-chan *pb.Status, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan *pb.Status, watcherBacklog)
+	if f.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	f.watchers[ch] = struct{}{}
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.drop(ch)
+	}
+}
+
+// Publish sends a notification to every watcher. It never blocks, watchers lagging behind are dropped
+func (f *Feed) Publish(status *pb.Status) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.watchers {
+		select {
+		case ch <- status:
+		default:
+			slog.Warn("[Status Feed] :: A watcher is too slow to keep up with the notifications, dropping it")
+			f.drop(ch)
+		}
+	}
+}
+
+// Close ends the feed for all watchers, once they have received the pending notifications
+func (f *Feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	for ch := range f.watchers {
+		f.drop(ch)
+	}
+}
+
+func (f *Feed) drop(ch chan *pb.Status) {
+	if _, ok := f.watchers[ch]; !ok {
+		return
+	}
+	delete(f.watchers, ch)
+	close(ch)
+}
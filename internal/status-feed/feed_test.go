@@ -0,0 +1,45 @@
+package status_feed
+
+import (
+	"github.com/stretchr/testify/assert"
+	pb "live-audio-mixer/proto"
+	"testing"
+)
+
+func TestFeed_Publish(t *testing.T) {
+	f := NewFeed()
+	first, unsubscribeFirst := f.Subscribe()
+	second, unsubscribeSecond := f.Subscribe()
+	defer unsubscribeSecond()
+	f.Publish(&pb.Status{Type: pb.StatusType_TRACK_STARTED, TrackId: "a"})
+	assert.Equal(t, "a", (<-first).TrackId)
+	unsubscribeFirst()
+	_, ok := <-first
+	assert.False(t, ok)
+
+	// Pending notifications are still received once the feed is closed
+	f.Publish(&pb.Status{Type: pb.StatusType_TRACK_ENDED, TrackId: "a"})
+	f.Close()
+	assert.Equal(t, pb.StatusType_TRACK_STARTED, (<-second).Type)
+	assert.Equal(t, pb.StatusType_TRACK_ENDED, (<-second).Type)
+	_, ok = <-second
+	assert.False(t, ok)
+
+	late, _ := f.Subscribe()
+	_, ok = <-late
+	assert.False(t, ok)
+}
+
+func TestFeed_DropSlowWatcher(t *testing.T) {
+	f := NewFeed()
+	statuses, unsubscribe := f.Subscribe()
+	defer unsubscribe()
+	for i := 0; i <= watcherBacklog; i++ {
+		f.Publish(&pb.Status{})
+	}
+	received := 0
+	for range statuses {
+		received++
+	}
+	assert.Equal(t, watcherBacklog, received)
+}
@@ -0,0 +1,194 @@
+package stream_handler
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/faiface/beep"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Extension of the assets in the cache, and of the assets being added to it
+	cachedExt  = ".flac"
+	partialExt = ".part"
+)
+
+// AssetCache keeps transcoded assets on disk, so that playing an asset again doesn't fetch and transcode it again.
+// Files are named after a hash of the URL and version of the asset, and of the format it is transcoded to,
+// so that an asset replaced at the same URL is transcoded again.
+// Once the cache is full, the least recently used assets are evicted
+type AssetCache struct {
+	dir string
+	// Maximum total size of the cached assets, in bytes
+	maxSize int64
+	mu      sync.Mutex
+	// Cached assets, the most recently used first
+	lru     *list.List
+	entries map[string]*list.Element
+	size    int64
+	// Assets being added to the cache
+	filling map[string]struct{}
+	// Versions of the assets fetched lately. They are shared by the handlers using the cache, so that an asset
+	// played again is found in the cache without being fetched again
+	versions *versions
+}
+
+type cachedAsset struct {
+	key  string
+	size int64
+}
+
+// NewAssetCache opens the cache stored in dir, creating it if needed. Assets already in dir are kept,
+// the most recently modified being considered the most recently used
+func NewAssetCache(dir string, maxSize int64) (*AssetCache, error) {
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	c := &AssetCache{
+		dir:      dir,
+		maxSize:  maxSize,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+		filling:  map[string]struct{}{},
+		versions: newVersions(versionTTL),
+	}
+	var cached []os.FileInfo
+	for _, file := range files {
+		name := file.Name()
+		if strings.HasSuffix(name, partialExt) {
+			// Left over by an interrupted fill
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		if file.IsDir() || !strings.HasSuffix(name, cachedExt) {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			return nil, err
+		}
+		cached = append(cached, info)
+	}
+	sort.Slice(cached, func(i, j int) bool { return cached[i].ModTime().After(cached[j].ModTime()) })
+	for _, info := range cached {
+		key := strings.TrimSuffix(info.Name(), cachedExt)
+		c.entries[key] = c.lru.PushBack(&cachedAsset{key: key, size: info.Size()})
+		c.size += info.Size()
+	}
+	c.evict()
+	slog.Info(fmt.Sprintf("[Asset cache] :: Using %s, holding %d assets for %d bytes", dir, c.lru.Len(), c.size))
+	return c, nil
+}
+
+// assetKey identifies a version of an asset transcoded to a given format
+func assetKey(url string, version string, format beep.Format) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%d", url, version, format.SampleRate, format.NumChannels)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Open returns the cached asset, if any
+func (c *AssetCache) Open(key string) (*os.File, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	f, err := os.Open(c.path(key))
+	if err != nil {
+		slog.Warn(fmt.Sprintf("[Asset cache] :: Cached asset %s can't be opened, dropping it : %v", key, err))
+		c.remove(elem)
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	// The order of use survives restarts
+	now := time.Now()
+	_ = os.Chtimes(c.path(key), now, now)
+	return f, true
+}
+
+// Fill adds an asset to the cache, fill being called to write it to the given path.
+// Nothing is done if the asset is already cached or being added. Assets larger than the whole cache are not kept
+func (c *AssetCache) Fill(key string, fill func(path string) error) {
+	c.mu.Lock()
+	_, cached := c.entries[key]
+	_, filling := c.filling[key]
+	if cached || filling {
+		c.mu.Unlock()
+		return
+	}
+	c.filling[key] = struct{}{}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.filling, key)
+		c.mu.Unlock()
+	}()
+
+	partial := filepath.Join(c.dir, key+partialExt)
+	defer os.Remove(partial)
+	if err := fill(partial); err != nil {
+		slog.Warn(fmt.Sprintf("[Asset cache] :: Asset %s couldn't be cached : %v", key, err))
+		return
+	}
+	info, err := os.Stat(partial)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("[Asset cache] :: Asset %s couldn't be cached : %v", key, err))
+		return
+	}
+	if info.Size() >= c.maxSize {
+		slog.Debug(fmt.Sprintf("[Asset cache] :: Asset %s is too large to be cached", key))
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Rename(partial, c.path(key)); err != nil {
+		slog.Warn(fmt.Sprintf("[Asset cache] :: Asset %s couldn't be cached : %v", key, err))
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cachedAsset{key: key, size: info.Size()})
+	c.size += info.Size()
+	c.evict()
+}
+
+// Size returns the total size of the cached assets, in bytes
+func (c *AssetCache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+// evict removes the least recently used assets until the cache fits in its maximum size.
+// Assets being played keep being readable until closed
+func (c *AssetCache) evict() {
+	for c.size > c.maxSize && c.lru.Len() > 0 {
+		elem := c.lru.Back()
+		if err := os.Remove(c.path(elem.Value.(*cachedAsset).key)); err != nil && !os.IsNotExist(err) {
+			slog.Warn(fmt.Sprintf("[Asset cache] :: Error while evicting asset %s : %v", elem.Value.(*cachedAsset).key, err))
+		}
+		c.remove(elem)
+	}
+}
+
+func (c *AssetCache) remove(elem *list.Element) {
+	asset := c.lru.Remove(elem).(*cachedAsset)
+	delete(c.entries, asset.key)
+	c.size -= asset.size
+}
+
+func (c *AssetCache) path(key string) string {
+	return filepath.Join(c.dir, key+cachedExt)
+}
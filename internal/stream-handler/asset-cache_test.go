@@ -0,0 +1,149 @@
+package stream_handler
+
+import (
+	"errors"
+	"github.com/faiface/beep"
+	"github.com/stretchr/testify/assert"
+	rt_encoder "live-audio-mixer/internal/rt-encoder"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fillWith returns a fill function writing size bytes
+func fillWith(size int) func(path string) error {
+	return func(path string) error {
+		return os.WriteFile(path, make([]byte, size), 0644)
+	}
+}
+
+func TestAssetCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewAssetCache(dir, 100)
+	assert.NoError(t, err)
+	_, ok := cache.Open("a")
+	assert.False(t, ok)
+
+	cache.Fill("a", fillWith(40))
+	cache.Fill("b", fillWith(40))
+	assert.Equal(t, int64(80), cache.Size())
+	// Filling an asset already cached does nothing
+	cache.Fill("a", func(string) error { t.Fatal("asset filled twice"); return nil })
+
+	// The least recently used asset is evicted
+	f, ok := cache.Open("a")
+	assert.True(t, ok)
+	assert.NoError(t, f.Close())
+	cache.Fill("c", fillWith(40))
+	assert.Equal(t, int64(80), cache.Size())
+	_, ok = cache.Open("b")
+	assert.False(t, ok)
+	assert.NoFileExists(t, filepath.Join(dir, "b"+cachedExt))
+
+	// Failed and oversized fills are not kept
+	cache.Fill("failed", func(string) error { return errors.New("unreachable") })
+	cache.Fill("large", fillWith(100))
+	_, ok = cache.Open("failed")
+	assert.False(t, ok)
+	_, ok = cache.Open("large")
+	assert.False(t, ok)
+	files, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	// The cache survives restarts, and keeps the order of use
+	time.Sleep(10 * time.Millisecond)
+	f, ok = cache.Open("a")
+	assert.True(t, ok)
+	assert.NoError(t, f.Close())
+	cache, err = NewAssetCache(dir, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(80), cache.Size())
+	cache.Fill("d", fillWith(40))
+	_, ok = cache.Open("c")
+	assert.False(t, ok)
+	_, ok = cache.Open("a")
+	assert.True(t, ok)
+}
+
+// writeRamp encodes a ramp of the given length to path, so that each sample tells its own position
+func writeRamp(path string, length int, format beep.Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	pos := 0
+	ramp := beep.Take(length, beep.StreamerFunc(func(samples [][2]float64) (n int, ok bool) {
+		for i := range samples {
+			samples[i] = [2]float64{float64(pos) / float64(length), 0}
+			pos++
+		}
+		return len(samples), true
+	}))
+	return rt_encoder.FlacEncode(f, ramp, format, make(chan os.Signal))
+}
+
+func TestOpenCached(t *testing.T) {
+	format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+	path := filepath.Join(t.TempDir(), "ramp"+cachedExt)
+	length := format.SampleRate.N(2 * time.Second)
+	assert.NoError(t, writeRamp(path, length, format))
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	s, _, err := openCached(f, 1234*time.Millisecond)
+	assert.NoError(t, err)
+	defer s.Close()
+	target := format.SampleRate.N(1234 * time.Millisecond)
+	assert.Equal(t, target, s.Position())
+	assert.Equal(t, length, s.Len())
+	samples := make([][2]float64, 10)
+	n, ok := s.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 10, n)
+	assert.InDelta(t, float64(target)/float64(length), samples[0][0], 1e-4)
+}
+
+// Source counting the assets it fetches
+type countingSource struct {
+	version string
+	fetched int
+}
+
+func (c *countingSource) Fetch(string) (*Asset, error) {
+	c.fetched++
+	return &Asset{Input: "unused", Version: c.version, Release: func() {}}, nil
+}
+
+// A cached asset is played again without being fetched, until its version expires
+func TestHandler_PlayCached(t *testing.T) {
+	cache, err := NewAssetCache(t.TempDir(), 1<<20)
+	assert.NoError(t, err)
+	src := &countingSource{version: "v1"}
+	h := NewCachedHandler(testFormat, cache, Sources{"test": src})
+	cache.Fill(assetKey("test://ramp", "v1", testFormat), func(path string) error {
+		return writeRamp(path, testFormat.SampleRate.N(time.Second), testFormat)
+	})
+
+	for i := 0; i < 3; i++ {
+		s, _, err := h.GetStream("test://ramp", 0)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Close())
+	}
+	assert.Equal(t, 1, src.fetched)
+	// The version is known without fetching the asset again
+	version, err := h.Version("test://ramp")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", version)
+	assert.Equal(t, 1, src.fetched)
+
+	cache.versions = newVersions(0)
+	s, _, err := h.GetStream("test://ramp", 0)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Close())
+	assert.Equal(t, 2, src.fetched)
+	_, ok := h.KnownVersion("test://ramp")
+	assert.False(t, ok)
+}
@@ -0,0 +1,281 @@
+package stream_handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/gabriel-vasile/mimetype"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ObjectScheme is the scheme of the assets held by the object storage, as in object://<key>
+const ObjectScheme = "object"
+
+// Duration the version of an asset is trusted for once fetched. Meanwhile, the asset is played from the cache
+// without being fetched again, and an asset replaced at the same URL is only noticed once it expires
+const versionTTL = time.Minute
+
+// Source gives access to the assets of a URL scheme
+type Source interface {
+	// Fetch checks that the asset is an audio asset, and makes it readable by ffmpeg
+	Fetch(assetUrl string) (*Asset, error)
+}
+
+// Asset is an asset made readable by ffmpeg
+type Asset struct {
+	// Input ffmpeg reads the asset from
+	Input string
+	// Changes whenever the content of the asset changes, so that an asset replaced at the same URL isn't
+	// played from the cache. Empty when unknown, the asset is then only cached until its version expires
+	Version string
+	// Called once the asset has been transcoded
+	Release func()
+}
+
+// Sources are the sources of assets by URL scheme
+type Sources map[string]Source
+
+// DefaultSources fetches public assets over HTTP. Local files and objects of the object storage are only
+// available once a source restricted to a part of them is added
+func DefaultSources() Sources {
+	return Sources{"http": httpSource{}, "https": httpSource{}}
+}
+
+// fetch fetches an asset from the source of its scheme
+func (s Sources) fetch(assetUrl string) (*Asset, error) {
+	scheme, _, ok := strings.Cut(assetUrl, "://")
+	src, known := s[strings.ToLower(scheme)]
+	if !ok || !known {
+		return nil, fmt.Errorf("unsupported scheme for audio with url %s", assetUrl)
+	}
+	return src.Fetch(assetUrl)
+}
+
+// httpSource lets ffmpeg fetch assets over HTTP
+type httpSource struct{}
+
+func (httpSource) Fetch(assetUrl string) (*Asset, error) {
+	// The headers are enough for most assets, the content is only downloaded to be sniffed when they don't tell
+	resp, err := http.Head(assetUrl)
+	if err == nil && (resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "audio/")) {
+		resp.Body.Close()
+		err = errors.New("no audio content type")
+	}
+	if err != nil {
+		resp, err = http.Get(assetUrl)
+		if err != nil {
+			return nil, err
+		}
+	}
+	defer resp.Body.Close()
+	// Determine the audio format based on the response content type
+	contentType := getMimeType(resp)
+	if !strings.HasPrefix(contentType, "audio/") {
+		slog.Warn(fmt.Sprintf("[Stream handler] :: Invalid content type: '%s' for audio with url %s. Aborting playback", contentType, assetUrl))
+		return nil, fmt.Errorf("invalid content type: '%s' for audio with url %s. Aborting playback", contentType, assetUrl)
+	}
+	// Servers telling neither are only trusted to serve the same content at the same URL until the version expires
+	version := resp.Header.Get("ETag")
+	if version == "" {
+		version = resp.Header.Get("Last-Modified")
+	}
+	return &Asset{Input: assetUrl, Version: version, Release: func() {}}, nil
+}
+
+func getMimeType(res *http.Response) string {
+	contentType := res.Header.Get("Content-Type")
+	// If the content type is not set in the request or somehow wrong, we double check it
+	if contentType == "" || !strings.HasPrefix(contentType, "audio/") {
+		mType, err := mimetype.DetectReader(res.Body)
+		if err != nil {
+			slog.Warn(fmt.Sprintf("[Stream handler] :: Error while detecting mime type of %s: %v", res.Request.URL, err))
+			return ""
+		}
+		contentType = mType.String()
+	}
+	return contentType
+}
+
+// fileSource reads assets from the local disk, as in file:///path/to/asset. Only files under its root can be read
+type fileSource struct {
+	root string
+}
+
+// NewFileSource reads assets from the local disk, under root
+func NewFileSource(root string) (Source, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSource{root: root}, nil
+}
+
+func (f *fileSource) Fetch(assetUrl string) (*Asset, error) {
+	u, err := url.Parse(assetUrl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return nil, fmt.Errorf("file url %s must be on the local host", assetUrl)
+	}
+	// Links are followed, so that they can't lead out of the root either
+	file, err := filepath.EvalSymlinks(filepath.Clean(u.Path))
+	if err != nil {
+		return nil, err
+	}
+	if rel, err := filepath.Rel(f.root, file); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file url %s is not under the asset directory", assetUrl)
+	}
+	if err := checkAudioFile(file); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	return &Asset{
+		// Without the prefix, ffmpeg would take a colon in the path for a protocol
+		Input:   "file:" + file,
+		Version: fmt.Sprintf("%d-%d", info.ModTime().UnixNano(), info.Size()),
+		Release: func() {},
+	}, nil
+}
+
+// Downloader downloads objects from an object storage
+type Downloader interface {
+	Download(key string, path string) error
+}
+
+// objectSource downloads assets from the object storage, so that they don't need to be public.
+// Only objects under its prefix can be downloaded
+type objectSource struct {
+	store  Downloader
+	prefix string
+}
+
+// NewObjectSource fetches assets from store. The key of the asset is prefix followed by everything after object://,
+// so that other objects, such as records, can't be played
+func NewObjectSource(store Downloader, prefix string) Source {
+	return &objectSource{store: store, prefix: prefix}
+}
+
+func (o *objectSource) Fetch(assetUrl string) (*Asset, error) {
+	// Cleaning the key as an absolute path drops any ".." leading out of the prefix
+	key := o.prefix + strings.TrimPrefix(path.Clean("/"+assetUrl[len(ObjectScheme+"://"):]), "/")
+	// The asset is downloaded to a temporary file, as some formats can only be read by seeking
+	f, err := os.CreateTemp("", "asset-*")
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	release := func() {
+		if err := os.Remove(f.Name()); err != nil {
+			slog.Warn(fmt.Sprintf("[Stream handler] :: Error while removing downloaded asset %s : %v", f.Name(), err))
+		}
+	}
+	err = o.store.Download(key, f.Name())
+	if err == nil {
+		err = checkAudioFile(f.Name())
+	}
+	var version string
+	if err == nil {
+		// The whole object is downloaded anyway, it is identified by its content
+		version, err = hashFile(f.Name())
+	}
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("audio with url %s couldn't be downloaded : %w", assetUrl, err)
+	}
+	return &Asset{Input: "file:" + f.Name(), Version: version, Release: release}, nil
+}
+
+// hashFile returns the SHA-256 of the content of a file
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// checkAudioFile checks that a local file is an audio file
+func checkAudioFile(path string) error {
+	mType, err := mimetype.DetectFile(path)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(mType.String(), "audio/") {
+		return fmt.Errorf("invalid content type: '%s' for audio file %s", mType.String(), path)
+	}
+	return nil
+}
+
+// versions remembers the version of the assets fetched lately, until it expires
+type versions struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*knownVersion
+}
+
+type knownVersion struct {
+	version string
+	// Set when the source didn't tell the version, it was made up when the asset was fetched
+	madeUp  bool
+	expires time.Time
+}
+
+func newVersions(ttl time.Duration) *versions {
+	return &versions{ttl: ttl, entries: map[string]*knownVersion{}}
+}
+
+// get returns the version of an asset, if it is known and not expired
+func (v *versions) get(assetUrl string) (string, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	known, ok := v.entries[assetUrl]
+	if !ok || time.Now().After(known.expires) {
+		return "", false
+	}
+	return known.version, true
+}
+
+// set records the version of an asset just fetched, and returns the version it is cached under.
+// Assets whose version is unknown get a version of their own, kept until it expires
+func (v *versions) set(assetUrl string, version string) string {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	for url, known := range v.entries {
+		if now.After(known.expires) {
+			delete(v.entries, url)
+		}
+	}
+	if version == "" {
+		if known, ok := v.entries[assetUrl]; ok && known.madeUp {
+			return known.version
+		}
+		v.entries[assetUrl] = &knownVersion{version: fmt.Sprintf("fetched-%d", now.UnixNano()), madeUp: true, expires: now.Add(v.ttl)}
+		return v.entries[assetUrl].version
+	}
+	v.entries[assetUrl] = &knownVersion{version: version, expires: now.Add(v.ttl)}
+	return version
+}
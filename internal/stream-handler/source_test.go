@@ -0,0 +1,155 @@
+package stream_handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	test_utils "live-audio-mixer/test-utils"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSource(t *testing.T) {
+	content, err := os.ReadFile(test_utils.GetResAbsolutePath(t, test_utils.Flac_Ensoniq))
+	assert.NoError(t, err)
+	root, outside := t.TempDir(), t.TempDir()
+	for _, path := range []string{filepath.Join(root, "asset.flac"), filepath.Join(outside, "asset.flac")} {
+		assert.NoError(t, os.WriteFile(path, content, 0644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "text.txt"), []byte("not an audio file"), 0644))
+	assert.NoError(t, os.Symlink(filepath.Join(outside, "asset.flac"), filepath.Join(root, "link.flac")))
+	files, err := NewFileSource(root)
+	assert.NoError(t, err)
+	sources := DefaultSources()
+	sources["file"] = files
+
+	asset, err := sources.fetch("file://" + filepath.Join(root, "asset.flac"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(asset.Input, "asset.flac"))
+	asset.Release()
+	assert.FileExists(t, filepath.Join(root, "asset.flac"))
+	// Replacing the file changes its version
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "asset.flac"), append(content, 0), 0644))
+	replaced, err := sources.fetch("file://" + filepath.Join(root, "asset.flac"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, asset.Version, replaced.Version)
+
+	for _, url := range []string{
+		"file://" + filepath.Join(root, "text.txt"),
+		"file://" + filepath.Join(root, "missing.flac"),
+		"file://remote-host" + filepath.Join(root, "asset.flac"),
+		// Files out of the root can't be played, even through a link
+		"file://" + filepath.Join(outside, "asset.flac"),
+		"file://" + root + "/../" + filepath.Base(outside) + "/asset.flac",
+		"file://" + filepath.Join(root, "link.flac"),
+	} {
+		_, err = sources.fetch(url)
+		assert.Error(t, err, url)
+	}
+	// Local files are not available by default
+	_, err = DefaultSources().fetch("file://" + filepath.Join(root, "asset.flac"))
+	assert.Error(t, err)
+}
+
+// Object storage holding the files of a directory
+type dirStore struct {
+	dir string
+	// Keys downloaded so far
+	keys []string
+}
+
+func (d *dirStore) Download(key string, path string) error {
+	d.keys = append(d.keys, key)
+	content, err := os.ReadFile(filepath.Join(d.dir, key))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+func TestObjectSource(t *testing.T) {
+	path := test_utils.GetResAbsolutePath(t, test_utils.Flac_Ensoniq)
+	store := &dirStore{dir: filepath.Dir(filepath.Dir(path))}
+	sources := DefaultSources()
+	sources[ObjectScheme] = NewObjectSource(store, filepath.Base(filepath.Dir(path))+"/")
+
+	asset, err := sources.fetch("object://" + filepath.Base(path))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(asset.Input, "file:"))
+	downloaded := strings.TrimPrefix(asset.Input, "file:")
+	assert.FileExists(t, downloaded)
+	// Objects are identified by their content
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	sum := sha256.Sum256(content)
+	assert.Equal(t, hex.EncodeToString(sum[:]), asset.Version)
+	asset.Release()
+	assert.NoFileExists(t, downloaded)
+
+	_, err = sources.fetch("object://missing.flac")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	// Objects out of the prefix can't be played
+	_, err = sources.fetch("object://../" + filepath.Base(filepath.Dir(path)) + "/../go.mod")
+	assert.Error(t, err)
+	assert.Equal(t, filepath.Base(filepath.Dir(path))+"/go.mod", store.keys[len(store.keys)-1])
+}
+
+func TestHttpSource(t *testing.T) {
+	content, err := os.ReadFile(test_utils.GetResAbsolutePath(t, test_utils.Flac_Ensoniq))
+	assert.NoError(t, err)
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		if r.URL.Path == "/tagged.flac" {
+			w.Header().Set("ETag", `"v1"`)
+		}
+		if r.URL.Path != "/untyped" {
+			w.Header().Set("Content-Type", "audio/flac")
+		}
+		_, _ = w.Write(content)
+	}))
+	defer server.Close()
+
+	// The headers are enough, the asset isn't downloaded
+	asset, err := DefaultSources().fetch(server.URL + "/tagged.flac")
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/tagged.flac", asset.Input)
+	assert.Equal(t, `"v1"`, asset.Version)
+	assert.Equal(t, []string{http.MethodHead}, methods)
+	// Assets without ETag nor Last-Modified have no known version
+	asset, err = DefaultSources().fetch(server.URL + "/untagged.flac")
+	assert.NoError(t, err)
+	assert.Empty(t, asset.Version)
+	// The content is sniffed when the content type doesn't tell
+	methods = nil
+	_, err = DefaultSources().fetch(server.URL + "/untyped")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{http.MethodHead, http.MethodGet}, methods)
+}
+
+func TestVersions(t *testing.T) {
+	v := newVersions(50 * time.Millisecond)
+	_, ok := v.get("a")
+	assert.False(t, ok)
+	assert.Equal(t, "v1", v.set("a", "v1"))
+	version, ok := v.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "v1", version)
+
+	// Assets without a version keep the one made up for them until it expires
+	madeUp := v.set("b", "")
+	assert.NotEmpty(t, madeUp)
+	assert.Equal(t, madeUp, v.set("b", ""))
+	time.Sleep(60 * time.Millisecond)
+	_, ok = v.get("a")
+	assert.False(t, ok)
+	assert.NotEqual(t, madeUp, v.set("b", ""))
+	// Expired versions are forgotten
+	assert.Len(t, v.entries, 1)
+}
@@ -2,10 +2,12 @@ package stream_handler
 
 import (
 	"fmt"
+	"github.com/faiface/beep"
 	"io"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type StreamConverter struct {
@@ -22,15 +24,16 @@ type NonSeekingReader struct {
 
 // NewStreamConverter creates a new stream converter
 // url: The url of the stream to convert
-// offsetSecs: The offset in seconds to start the conversion from. A negative value will have no effect. A greater than the length of the stream may have unexpected results
-func NewStreamConverter(url string, offsetSecs int) *StreamConverter {
-	args := []string{"-i", url, "-vn", "-ac", "2", "-ar", "48000", "-acodec", "flac", "-f", "flac", "-"}
+// offset: The offset to start the conversion from, to the millisecond. A negative value will have no effect. A greater than the length of the stream may have unexpected results
+// format: The sample rate and number of channels to convert the stream to
+func NewStreamConverter(url string, offset time.Duration, format beep.Format) *StreamConverter {
+	args := append(flacArgs(url, format), "-")
 
 	// We don't add this option be default as the -ss option can result in corrupted audio
 	// depending on the input format
 	// As most of the song will be played with an offset of 0, we don't want to add this option by default
-	if offsetSecs > 0 {
-		args = append([]string{"-ss", strconv.Itoa(offsetSecs) + "s"}, args...)
+	if offset > 0 {
+		args = append([]string{"-ss", strconv.FormatInt(offset.Milliseconds(), 10) + "ms"}, args...)
 	}
 
 	return &StreamConverter{
@@ -47,6 +50,23 @@ func NewStreamConverter(url string, offsetSecs int) *StreamConverter {
 
 }
 
+// Transcode converts a whole asset, read from the given ffmpeg input, to a FLAC file at path, in the sample rate and number of channels of format.
+// As the output is seekable, the FLAC header holds the length of the asset. Transcoding stops once the file
+// reaches maxSize bytes, in which case the file is truncated
+func Transcode(input string, format beep.Format, path string, maxSize int64) error {
+	args := append(flacArgs(input, format), "-fs", strconv.FormatInt(maxSize, 10), "-y", path)
+	out, err := exec.Command("ffmpeg", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, out)
+	}
+	return nil
+}
+
+// Arguments of ffmpeg converting an asset to FLAC, without the output
+func flacArgs(url string, format beep.Format) []string {
+	return []string{"-i", url, "-vn", "-ac", strconv.Itoa(format.NumChannels), "-ar", strconv.Itoa(int(format.SampleRate)), "-acodec", "flac", "-f", "flac"}
+}
+
 func (s *StreamConverter) GetOutput() (pipe *NonSeekingReader, err error) {
 	stdout, err := s.cmd.StdoutPipe()
 	if err != nil {
@@ -2,6 +2,7 @@ package stream_handler
 
 import (
 	"fmt"
+	"github.com/faiface/beep"
 	"github.com/faiface/beep/flac"
 	"github.com/faiface/beep/speaker"
 	"github.com/stretchr/testify/assert"
@@ -13,6 +14,9 @@ import (
 	"time"
 )
 
+// Format streams are converted to in tests
+var testFormat = beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+
 var testCases = []string{
 	"https://s3.amazonaws.com/cdn.roll20.net/ttaudio/148_Barovian_Castle.mp3",
 	"https://freetestdata.com/wp-content/uploads/2021/09/Free_Test_Data_2MB_OGG.ogg",
@@ -22,7 +26,7 @@ var testCases = []string{
 func TestStreamConverter_StartNoError(t *testing.T) {
 	for _, testLink := range testCases {
 		t.Run(fmt.Sprintf("Testing valid link %s", testLink), func(t *testing.T) {
-			convert := NewStreamConverter(testLink, 0)
+			convert := NewStreamConverter(testLink, 0, testFormat)
 			stdout, err := convert.GetOutput()
 			assert.NoError(t, err)
 			errCh := make(chan error)
@@ -49,7 +53,7 @@ func TestStreamConverter_StartNoError(t *testing.T) {
 func TestStreamConverter_StartNoError_InvalidOffsetNeg(t *testing.T) {
 	for _, testLink := range testCases {
 		t.Run(fmt.Sprintf("Testing valid link %s", testLink), func(t *testing.T) {
-			convert := NewStreamConverter(testLink, -855)
+			convert := NewStreamConverter(testLink, -855*time.Second, testFormat)
 			stdout, err := convert.GetOutput()
 			assert.NoError(t, err)
 			errCh := make(chan error)
@@ -78,7 +82,7 @@ func TestStreamConverter_StartNoError_InvalidOffsetNeg(t *testing.T) {
 func TestStreamConverter_StartNoError_InvalidOffsetTooLong(t *testing.T) {
 	for _, testLink := range testCases {
 		t.Run(fmt.Sprintf("Testing valid link %s", testLink), func(t *testing.T) {
-			convert := NewStreamConverter(testLink, 3600)
+			convert := NewStreamConverter(testLink, 3600*time.Second, testFormat)
 			stdout, err := convert.GetOutput()
 			assert.NoError(t, err)
 			errCh := make(chan error)
@@ -102,7 +106,7 @@ func TestStreamConverter_StartNoError_InvalidOffsetTooLong(t *testing.T) {
 }
 
 func TestStreamConverter_StartError(t *testing.T) {
-	convert := NewStreamConverter("http://garbage.com", 0)
+	convert := NewStreamConverter("http://garbage.com", 0, testFormat)
 	stdout, err := convert.GetOutput()
 	assert.NoError(t, err)
 	errCh := make(chan error)
@@ -131,7 +135,7 @@ func TestStreamConverter_Listen(t *testing.T) {
 	}
 	for _, testLink := range testCases {
 		t.Run(fmt.Sprintf("Testing valid link %s", testLink), func(t *testing.T) {
-			convert := NewStreamConverter(testLink, 3)
+			convert := NewStreamConverter(testLink, 3*time.Second, testFormat)
 			pipe, err := convert.GetOutput()
 			assert.NoError(t, err)
 			errCh := make(chan error)
@@ -154,7 +158,7 @@ func TestStreamConverter_Listen(t *testing.T) {
 func TestStreamConverter_Release(t *testing.T) {
 	for _, testLink := range testCases {
 		t.Run(fmt.Sprintf("Testing valid link %s", testLink), func(t *testing.T) {
-			convert := NewStreamConverter(testLink, 0)
+			convert := NewStreamConverter(testLink, 0, testFormat)
 			pipe, err := convert.GetOutput()
 			assert.NoError(t, err)
 			errCh := make(chan error)
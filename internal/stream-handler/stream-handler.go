@@ -4,57 +4,153 @@ import (
 	"fmt"
 	"github.com/faiface/beep"
 	"github.com/faiface/beep/flac"
-	"github.com/gabriel-vasile/mimetype"
 	"log/slog"
-	"net/http"
+	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Handler struct {
+	// Format every stream is converted to
+	format beep.Format
+	// Transcoded assets, nil when assets are fetched and transcoded every time they are played
+	cache *AssetCache
+	// Where assets are fetched from, by URL scheme
+	sources Sources
 }
 
-func NewHandler() *Handler {
-	return &Handler{}
+// NewHandler creates a new handler, converting every stream to the sample rate and number of channels of format.
+// Assets are fetched from the default sources
+func NewHandler(format beep.Format) *Handler {
+	return &Handler{format: format, sources: DefaultSources()}
+}
+
+// NewCachedHandler creates a new handler keeping the transcoded assets in cache, and fetching them from sources,
+// nil meaning the default sources. The first time a version of an asset is played, it is streamed as usual while
+// being added to the cache in the background
+func NewCachedHandler(format beep.Format, cache *AssetCache, sources Sources) *Handler {
+	if sources == nil {
+		sources = DefaultSources()
+	}
+	return &Handler{format: format, cache: cache, sources: sources}
 }
 
 // GetStream takes an audio URL and returns a beep stream, format and error
 func (h *Handler) GetStream(audioUrl string, offset time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
-	// Fetch the audio file from the URL
-	resp, err := http.Get(audioUrl)
+	// An asset fetched lately is played from the cache without being fetched again, until its version expires
+	if h.cache != nil {
+		if version, ok := h.cache.versions.get(audioUrl); ok {
+			if s, format, ok := h.playCached(audioUrl, assetKey(audioUrl, version, h.format), offset); ok {
+				return s, format, nil
+			}
+		}
+	}
+	asset, err := h.sources.fetch(audioUrl)
 	if err != nil {
-		fmt.Println("Error fetching audio:", err)
 		return nil, beep.Format{}, err
 	}
+	var key string
+	if h.cache != nil {
+		key = assetKey(audioUrl, h.cache.versions.set(audioUrl, asset.Version), h.format)
+		if s, format, ok := h.playCached(audioUrl, key, offset); ok {
+			asset.Release()
+			return s, format, nil
+		}
+	}
 
-	// Note, we don't need to close the stream directly, the Close method of the decoder will do it for us
-	// Determine the audio format based on the response content type
-	contentType := h.getMimeType(resp)
-	if !strings.HasPrefix(contentType, "audio/") {
-		slog.Warn(fmt.Sprintf("[Stream handler] :: Invalid content type: '%s' for audio with url %s. Aborting playback", contentType, audioUrl))
-		return nil, beep.Format{}, fmt.Errorf("invalid content type: '%s' for audio with url %s. Aborting playback", contentType, audioUrl)
-	}
-	sc := NewStreamConverter(audioUrl, int(offset.Seconds()))
+	// The asset is released once both the stream and the cache are done with it
+	var users sync.WaitGroup
+	if key != "" {
+		users.Add(1)
+		go func() {
+			defer users.Done()
+			h.cache.Fill(key, func(path string) error {
+				return Transcode(asset.Input, h.format, path, h.cache.maxSize)
+			})
+		}()
+	}
+	sc := NewStreamConverter(asset.Input, offset, h.format)
 	pipe, err := sc.GetOutput()
+	if err == nil {
+		users.Add(1)
+		go func() {
+			defer users.Done()
+			watchEncoder(audioUrl, sc)
+		}()
+	}
+	go func() {
+		users.Wait()
+		asset.Release()
+	}()
 	if err != nil {
 		return nil, beep.Format{}, err
 	}
-	go watchEncoder(audioUrl, sc)
 	return flac.Decode(pipe)
 }
 
-func (h *Handler) getMimeType(res *http.Response) string {
-	contentType := res.Header.Get("Content-Type")
-	// If the content type is not set in the request or somehow wrong, we double check it
-	if contentType == "" || !strings.HasPrefix(contentType, "audio/") {
-		mType, err := mimetype.DetectReader(res.Body)
-		if err != nil {
-			slog.Warn(fmt.Sprintf("[Stream handler] :: Error while detecting mime type of %s: %v", res.Request.URL, err))
-			return ""
+// Version returns the version of an asset, which changes whenever its content changes. The asset is fetched,
+// unless the cache knows a version fetched lately
+func (h *Handler) Version(audioUrl string) (string, error) {
+	if version, ok := h.KnownVersion(audioUrl); ok {
+		return version, nil
+	}
+	asset, err := h.sources.fetch(audioUrl)
+	if err != nil {
+		return "", err
+	}
+	asset.Release()
+	if h.cache == nil {
+		return asset.Version, nil
+	}
+	return h.cache.versions.set(audioUrl, asset.Version), nil
+}
+
+// KnownVersion returns the version of an asset if the cache knows a version fetched lately, without fetching it
+func (h *Handler) KnownVersion(audioUrl string) (string, bool) {
+	if h.cache == nil {
+		return "", false
+	}
+	return h.cache.versions.get(audioUrl)
+}
+
+// playCached opens the cached asset under key from the given offset, if it is in the cache and can be read
+func (h *Handler) playCached(audioUrl string, key string, offset time.Duration) (beep.StreamSeekCloser, beep.Format, bool) {
+	f, ok := h.cache.Open(key)
+	if !ok {
+		return nil, beep.Format{}, false
+	}
+	s, format, err := openCached(f, offset)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("[Stream handler] :: Cached asset for %s can't be read, transcoding it again : %v", audioUrl, err))
+		return nil, beep.Format{}, false
+	}
+	slog.Debug(fmt.Sprintf("[Stream handler] :: Playing %s from the cache", audioUrl))
+	return s, format, true
+}
+
+// openCached decodes a cached asset from the given offset. Unlike transcoded streams, it is seekable to the sample
+func openCached(f *os.File, offset time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	s, format, err := flac.Decode(f)
+	if err != nil {
+		return nil, beep.Format{}, err
+	}
+	if offset <= 0 {
+		return s, format, nil
+	}
+	// Seeking lands on the start of the frame holding the target sample, the rest of the frame is skipped
+	target := format.SampleRate.N(offset)
+	if err := s.Seek(target); err != nil {
+		s.Close()
+		return nil, beep.Format{}, err
+	}
+	skipped := make([][2]float64, 512)
+	for s.Position() < target {
+		if _, ok := s.Stream(skipped[:min(len(skipped), target-s.Position())]); !ok {
+			break
 		}
-		contentType = mType.String()
 	}
-	return contentType
+	return s, format, nil
 }
 
 func watchEncoder(url string, sc *StreamConverter) {
@@ -56,7 +56,7 @@ func setup(t *testing.T) []getStreamCase {
 	}
 }
 func TestGetStream(t *testing.T) {
-	h := NewHandler()
+	h := NewHandler(testFormat)
 	cases := setup(t)
 	for _, testCase := range cases {
 		testName := fmt.Sprintf("Testing valid link %s", testCase.link)
@@ -98,7 +98,7 @@ func TestGetStream_Cmp(t *testing.T) {
 	cases := setup(t)
 	for _, testCase := range cases {
 		t.Run(fmt.Sprintf("Testing link %s", testCase.link), func(t *testing.T) {
-			h := NewHandler()
+			h := NewHandler(testFormat)
 			// Open the candidate stream and get the required number of sample
 			candidate, format, err := h.GetStream(testCase.link, testCase.offset)
 			assert.NoError(t, err)
@@ -120,10 +120,9 @@ func TestHandler_GetMimeType(t *testing.T) {
 	cases := setup(t)
 	for _, testCase := range cases {
 		t.Run(fmt.Sprintf("Testing link %s", testCase.link), func(t *testing.T) {
-			h := NewHandler()
 			res, err := http.Get(testCase.link)
 			assert.NoError(t, err)
-			mime := h.getMimeType(res)
+			mime := getMimeType(res)
 			assert.Equal(t, testCase.mime, mime)
 		})
 	}
@@ -137,7 +136,7 @@ func TestGetStream_Listen(t *testing.T) {
 	cases := setup(t)
 	for _, testCase := range cases {
 		t.Run(fmt.Sprintf("Testing link %s", testCase.link), func(t *testing.T) {
-			h := NewHandler()
+			h := NewHandler(testFormat)
 			// Open the candidate stream and get the required number of sample
 			candidate, format, err := h.GetStream(testCase.link, 10*time.Second)
 			assert.NoError(t, err)
@@ -153,13 +152,13 @@ func TestGetStream_Listen(t *testing.T) {
 }
 
 func TestHandler_GetStream_NotAnAudioFile(t *testing.T) {
-	h := NewHandler()
+	h := NewHandler(testFormat)
 	_, _, err := h.GetStream("https://www.google.com", time.Second)
 	assert.Error(t, err)
 }
 
 func TestHandler_GetStream_InvalidLink(t *testing.T) {
-	h := NewHandler()
+	h := NewHandler(testFormat)
 	_, _, err := h.GetStream("fhdfhdfhhfd://garbage.com", time.Second)
 	assert.Error(t, err)
 }
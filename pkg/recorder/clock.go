@@ -0,0 +1,318 @@
+package recorder
+
+import (
+	"fmt"
+	"github.com/faiface/beep"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// clock drives the mix. It pulls fixed-size blocks from the source at the pace of the wall clock,
+// and pushes them to the sinks. The position in the mix is thus only tied to the time elapsed since
+// the clock started, and not to the way each encoder buffers its input
+type clock struct {
+	src  beep.Streamer
+	rate beep.SampleRate
+	// Number of samples pulled at each tick
+	block int
+	push  func(samples [][2]float64)
+	stop  chan struct{}
+	done  chan struct{}
+	mu    sync.Mutex
+	// How late the last block was produced, compared to the wall clock
+	drift time.Duration
+	// Reception and application time of the oldest event not yet included in a block
+	pendingReceived, pendingApplied time.Time
+	// Delay between the reception of the last event and the moment it was included in a block
+	latency time.Duration
+	// Wall-clock time of the start of the mix, shifted by the duration of the pauses
+	started time.Time
+	// Wall-clock time the clock was started at
+	origin time.Time
+	// Number of samples produced so far, which is the current position in the mix
+	produced int
+	// Actions scheduled at a given position, sorted by position and then by scheduling order
+	actions []action
+	// An offline clock renders the mix as fast as possible instead of following the wall clock,
+	// up to the limit position. onLimit is then called
+	offline bool
+	limit   int
+	onLimit func()
+	// A paused clock produces no blocks. The wall-clock start of the mix is shifted on resume,
+	// so that the pause doesn't show in the mix
+	paused   bool
+	pausedAt time.Time
+	// Wakes the clock up when it is resumed
+	resumed chan struct{}
+}
+
+// action is a function the clock calls when reaching a given position in the mix
+type action struct {
+	pos int
+	fn  func()
+}
+
+func newClock(src beep.Streamer, rate beep.SampleRate, block time.Duration, push func(samples [][2]float64)) *clock {
+	return &clock{
+		src:     src,
+		rate:    rate,
+		block:   rate.N(block),
+		push:    push,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		resumed: make(chan struct{}, 1),
+	}
+}
+
+// start runs the clock in the background until halt is called
+func (c *clock) start() {
+	c.mu.Lock()
+	c.started = time.Now()
+	c.origin = c.started
+	c.mu.Unlock()
+	go c.run()
+}
+
+// halt stops the clock, and waits for the block being produced, if any
+func (c *clock) halt() {
+	select {
+	case <-c.stop:
+	default:
+		close(c.stop)
+	}
+	<-c.done
+}
+
+// pause stops producing blocks until resume is called. It returns false if the clock is already paused
+func (c *clock) pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		return false
+	}
+	c.paused, c.pausedAt = true, time.Now()
+	return true
+}
+
+// resume produces blocks again, right from where the clock was paused. It returns false if the clock is not paused
+func (c *clock) resume() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		return false
+	}
+	c.paused = false
+	c.started = c.started.Add(time.Since(c.pausedAt))
+	select {
+	case c.resumed <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// isPaused returns whether the clock is paused
+func (c *clock) isPaused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// Drift returns how late the mix is compared to the wall clock
+func (c *clock) Drift() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.drift
+}
+
+// Latency returns the delay between the reception of the last event and the moment it was included in the mix
+func (c *clock) Latency() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latency
+}
+
+// eventApplied notifies the clock that an event received at the given time has just been applied to the source.
+// It is heard in the first block rendered from now on
+func (c *clock) eventApplied(received time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pendingApplied.IsZero() {
+		c.pendingReceived, c.pendingApplied = received, time.Now()
+	}
+}
+
+// position returns the position of the next block to be rendered
+func (c *clock) position() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.produced
+}
+
+// startTime returns the wall-clock time the mix was started at
+func (c *clock) startTime() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.origin
+}
+
+// positionAt returns the position in the mix matching a wall-clock time
+func (c *clock) positionAt(t time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rate.N(t.Sub(c.started))
+}
+
+// schedule calls fn when the mix reaches the given position, right before rendering the sample at this position.
+// Actions scheduled at the same position are called in scheduling order.
+// It returns false if the position has already been rendered, in which case fn is called as soon as possible
+func (c *clock) schedule(pos int, fn func()) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := sort.Search(len(c.actions), func(i int) bool { return c.actions[i].pos > pos })
+	c.actions = slices.Insert(c.actions, i, action{pos: pos, fn: fn})
+	return pos >= c.produced
+}
+
+// fireDue calls every action scheduled up to the given position, and returns the position of the next one, if any
+func (c *clock) fireDue(pos int) (next int, ok bool) {
+	c.mu.Lock()
+	i := sort.Search(len(c.actions), func(i int) bool { return c.actions[i].pos > pos })
+	due := c.actions[:i:i]
+	c.actions = c.actions[i:]
+	c.mu.Unlock()
+	// Actions may use the source, they must be called without holding the lock
+	for _, a := range due {
+		a.fn()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.actions) == 0 {
+		return 0, false
+	}
+	return c.actions[0].pos, true
+}
+
+// render fills samples from the source, starting at the given position in the mix.
+// The rendering is split at the position of each scheduled action, so that actions are sample-accurate
+func (c *clock) render(samples [][2]float64, pos int) {
+	filled := 0
+	for filled < len(samples) {
+		end := len(samples)
+		if next, ok := c.fireDue(pos + filled); ok && next-pos < end {
+			// An action may have been scheduled in the past meanwhile, it is called in the next iteration
+			end = max(next-pos, filled)
+			if end == filled {
+				continue
+			}
+		}
+		n, ok := c.src.Stream(samples[filled:end])
+		if !ok {
+			n = 0
+		}
+		// The mix must go on, whatever the source does
+		for i := filled + n; i < end; i++ {
+			samples[i] = [2]float64{}
+		}
+		filled = end
+	}
+}
+
+func (c *clock) run() {
+	defer close(c.done)
+	if c.offline {
+		c.runOffline()
+		return
+	}
+	var (
+		samples  = make([][2]float64, c.block)
+		timer    = time.NewTimer(time.Hour)
+		produced int
+		lagging  bool
+	)
+	// The timer is only armed while waiting for the next block
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		start, paused := c.started, c.paused
+		c.mu.Unlock()
+		if paused {
+			select {
+			case <-c.stop:
+				return
+			case <-c.resumed:
+			}
+			continue
+		}
+		// Each block is due when the wall clock reaches its position in the mix
+		due := start.Add(c.rate.D(produced))
+		timer.Reset(time.Until(due))
+		select {
+		case <-c.stop:
+			return
+		case <-timer.C:
+		}
+		c.mu.Lock()
+		if c.paused || !c.started.Equal(start) {
+			// Paused while waiting, the block is not due anymore
+			c.mu.Unlock()
+			continue
+		}
+		drift := time.Since(due)
+		c.drift = drift
+		c.mu.Unlock()
+		// When late, blocks are produced back to back until the mix catches up
+		if drift > maxDrift && !lagging {
+			slog.Warn(fmt.Sprintf("[Recorder] :: Mixing clock is %v behind the wall clock", drift))
+			lagging = true
+		} else if drift <= maxDrift && lagging {
+			slog.Info("[Recorder] :: Mixing clock caught up with the wall clock")
+			lagging = false
+		}
+
+		rendering := time.Now()
+		c.render(samples, produced)
+		c.push(samples)
+		produced += len(samples)
+		c.mu.Lock()
+		c.produced = produced
+		c.mu.Unlock()
+		c.measureLatency(rendering)
+	}
+}
+
+// runOffline renders the mix as fast as the sinks can take it
+func (c *clock) runOffline() {
+	samples := make([][2]float64, c.block)
+	produced := 0
+	for produced < c.limit {
+		select {
+		case <-c.stop:
+			return
+		default:
+		}
+		block := samples[:min(len(samples), c.limit-produced)]
+		c.render(block, produced)
+		c.push(block)
+		produced += len(block)
+		c.mu.Lock()
+		c.produced = produced
+		c.mu.Unlock()
+	}
+	c.onLimit()
+}
+
+// measureLatency updates the latency if the pending event was applied before the block rendered at the given time
+func (c *clock) measureLatency(rendering time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pendingApplied.IsZero() || c.pendingApplied.After(rendering) {
+		return
+	}
+	c.latency = time.Since(c.pendingReceived)
+	c.pendingReceived, c.pendingApplied = time.Time{}, time.Time{}
+	slog.Debug(fmt.Sprintf("[Recorder] :: Event heard in the mix %v after being received", c.latency))
+}
@@ -0,0 +1,142 @@
+package recorder
+
+import (
+	"github.com/stretchr/testify/assert"
+	test_utils "live-audio-mixer/test-utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The clock produces samples at the pace of the wall clock, in fixed-size blocks
+func TestClock_Pace(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		pushed []int
+	)
+	c := newClock(&test_utils.Ramp{}, 48000, 10*time.Millisecond, func(samples [][2]float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		pushed = append(pushed, len(samples))
+		// Blocks are contiguous
+		assert.Equal(t, float64((len(pushed)-1)*480), samples[0][0])
+	})
+	c.start()
+	time.Sleep(500 * time.Millisecond)
+	c.halt()
+
+	mu.Lock()
+	defer mu.Unlock()
+	// About 50 blocks of 480 samples in 500ms
+	assert.InDelta(t, 50, len(pushed), 3)
+	for _, n := range pushed {
+		assert.Equal(t, 480, n)
+	}
+	assert.Less(t, c.Drift(), maxDrift)
+}
+
+// A late clock catches up by producing blocks back to back
+func TestClock_CatchUp(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		samples int
+		once    sync.Once
+	)
+	c := newClock(&test_utils.Ramp{}, 48000, 10*time.Millisecond, func(s [][2]float64) {
+		// The first block takes way too long
+		once.Do(func() { time.Sleep(200 * time.Millisecond) })
+		mu.Lock()
+		defer mu.Unlock()
+		samples += len(s)
+	})
+	c.start()
+	time.Sleep(500 * time.Millisecond)
+	c.halt()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.InDelta(t, 48000/2, samples, 48000/20)
+}
+
+// Scheduled actions are called right before rendering the sample at their position
+func TestClock_Schedule(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		mix   [][2]float64
+		order []int
+	)
+	src := &test_utils.Constant{}
+	c := newClock(src, 48000, 10*time.Millisecond, func(samples [][2]float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		mix = append(mix, samples...)
+	})
+	// Not aligned on a block boundary
+	c.schedule(1234, func() {
+		src.Sample = [2]float64{1, 1}
+		order = append(order, 1)
+	})
+	c.schedule(1234, func() { order = append(order, 2) })
+	c.schedule(600, func() { order = append(order, 0) })
+	c.start()
+	time.Sleep(100 * time.Millisecond)
+	// Scheduling in the past calls the action as soon as possible
+	assert.False(t, c.schedule(10, func() { src.Sample = [2]float64{2, 2} }))
+	time.Sleep(50 * time.Millisecond)
+	c.halt()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{0, 1, 2}, order)
+	assert.Equal(t, 0.0, mix[1233][0])
+	assert.Equal(t, 1.0, mix[1234][0])
+	assert.Equal(t, 2.0, mix[len(mix)-1][0])
+}
+
+// No time passes in the mix while paused, and the clock doesn't catch up once resumed
+func TestClock_Pause(t *testing.T) {
+	c := newClock(&test_utils.Constant{}, 48000, 10*time.Millisecond, func(samples [][2]float64) {})
+	c.start()
+	defer c.halt()
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, c.pause())
+	assert.False(t, c.pause())
+	// Let the block being produced, if any, complete
+	time.Sleep(20 * time.Millisecond)
+	paused := c.position()
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, paused, c.position())
+	assert.True(t, c.resume())
+	assert.False(t, c.resume())
+	time.Sleep(50 * time.Millisecond)
+	assert.InDelta(t, 48000*120/1000, c.position(), 48000*40/1000)
+	assert.Less(t, c.Drift(), maxDrift)
+}
+
+// An offline clock renders up to its limit without waiting for the wall clock
+func TestClock_Offline(t *testing.T) {
+	var mix [][2]float64
+	src := &test_utils.Constant{}
+	c := newClock(src, 48000, 10*time.Millisecond, func(samples [][2]float64) {
+		mix = append(mix, samples...)
+	})
+	c.offline = true
+	// Two minutes of mix, not a whole number of blocks
+	c.limit = 48000*120 + 100
+	done := make(chan struct{})
+	c.onLimit = func() { close(done) }
+	c.schedule(48000*60, func() { src.Sample = [2]float64{1, 1} })
+	started := time.Now()
+	c.start()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	c.halt()
+	assert.Less(t, time.Since(started), 10*time.Second)
+	assert.Len(t, mix, c.limit)
+	assert.Equal(t, 0.0, mix[48000*60-1][0])
+	assert.Equal(t, 1.0, mix[48000*60][0])
+	assert.Equal(t, c.limit, c.position())
+}
@@ -0,0 +1,154 @@
+package recorder
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// fanOut shares the mix between multiple readers, each reading at its own pace.
+// Samples are pushed by the mixing clock, and are kept until every reader has consumed them.
+// A reader lagging behind by more than the capacity of the buffer is detached, so that
+// a stalled reader cannot prevent the others from progressing.
+type fanOut struct {
+	mu sync.Mutex
+	// Signaled whenever samples are pushed, or a reader is detached
+	cond *sync.Cond
+	// Samples pushed and not yet consumed by every reader
+	buf [][2]float64
+	// Absolute position in the mix of buf[0]
+	start int
+	// Maximum number of samples kept in the buffer
+	capacity int
+	readers  map[*fanOutReader]struct{}
+	// No samples will be pushed anymore
+	closed bool
+	// When blocking, pushing waits for the slowest reader instead of detaching it. Used when rendering
+	// faster than real time, as the readers are then the only ones setting the pace
+	blocking bool
+	// The pusher is waiting for the readers to make room
+	pushWaiting bool
+}
+
+type fanOutReader struct {
+	f *fanOut
+	// Absolute position of the next sample to read
+	pos int
+	// Name of the reader, for logging purposes
+	name     string
+	detached bool
+}
+
+func newFanOut(capacity int) *fanOut {
+	f := &fanOut{
+		capacity: capacity,
+		readers:  map[*fanOutReader]struct{}{},
+	}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// newReader attaches a new reader, starting at the head of the stream
+func (f *fanOut) newReader(name string) *fanOutReader {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	r := &fanOutReader{f: f, pos: f.end(), name: name}
+	f.readers[r] = struct{}{}
+	return r
+}
+
+// push appends samples to the stream, waking up the readers waiting for them
+func (f *fanOut) push(samples [][2]float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for f.blocking && len(f.readers) > 0 && f.end()+len(samples)-f.minPos() > f.capacity {
+		// Readers waiting for more samples than can be buffered must be released
+		f.pushWaiting = true
+		f.cond.Broadcast()
+		f.cond.Wait()
+	}
+	f.pushWaiting = false
+	f.buf = append(f.buf, samples...)
+	f.trim()
+	f.cond.Broadcast()
+}
+
+// close ends the stream. Readers still get the remaining samples, and are then drained
+func (f *fanOut) close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.cond.Broadcast()
+}
+
+// Absolute position of the head of the stream
+func (f *fanOut) end() int {
+	return f.start + len(f.buf)
+}
+
+// Stream implements beep.Streamer. It blocks until enough samples have been pushed, or until the pusher
+// is waiting for this reader to make room. Once detached, or once the stream is closed and consumed, a reader is drained
+func (r *fanOutReader) Stream(samples [][2]float64) (n int, ok bool) {
+	f := r.f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for !r.detached && !f.closed && f.end()-r.pos < len(samples) && !(f.pushWaiting && f.end() > r.pos) {
+		f.cond.Wait()
+	}
+	if r.detached {
+		return 0, false
+	}
+	n = copy(samples, f.buf[r.pos-f.start:])
+	r.pos += n
+	f.trim()
+	if f.blocking {
+		// Room has been made for the pusher
+		f.cond.Broadcast()
+	}
+	return n, n > 0
+}
+
+func (r *fanOutReader) Err() error {
+	return nil
+}
+
+// Detach removes the reader from the fan-out, it won't hold back other readers anymore
+func (r *fanOutReader) Detach() {
+	r.f.mu.Lock()
+	defer r.f.mu.Unlock()
+	r.f.detach(r)
+}
+
+func (f *fanOut) detach(r *fanOutReader) {
+	r.detached = true
+	delete(f.readers, r)
+	f.trim()
+	f.cond.Broadcast()
+}
+
+// trim drops the samples already consumed by every reader, and detaches readers lagging too far behind
+func (f *fanOut) trim() {
+	for r := range f.readers {
+		if f.end()-r.pos > f.capacity {
+			slog.Warn(fmt.Sprintf("[Recorder] :: Sink %s is lagging behind by more than %d samples, detaching it", r.name, f.capacity))
+			r.detached = true
+			delete(f.readers, r)
+		}
+	}
+	minPos := f.minPos()
+	if minPos > f.start {
+		f.buf = f.buf[minPos-f.start:]
+		f.start = minPos
+	}
+}
+
+// Position of the slowest reader, or of the head of the stream without any reader
+func (f *fanOut) minPos() int {
+	minPos := f.end()
+	for r := range f.readers {
+		if r.pos < minPos {
+			minPos = r.pos
+		}
+	}
+	return minPos
+}
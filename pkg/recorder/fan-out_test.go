@@ -0,0 +1,108 @@
+package recorder
+
+import (
+	"github.com/stretchr/testify/assert"
+	test_utils "live-audio-mixer/test-utils"
+	"testing"
+	"time"
+)
+
+// Every reader gets the same samples, whatever the size of its reads
+func TestFanOut_SameSamples(t *testing.T) {
+	f := newFanOut(1000)
+	r1, r2 := f.newReader("r1"), f.newReader("r2")
+	src := &test_utils.Ramp{}
+	f.push(test_utils.Pull(src, 120))
+	big := make([][2]float64, 100)
+	n, ok := r1.Stream(big)
+	assert.True(t, ok)
+	assert.Equal(t, 100, n)
+	assert.Equal(t, 99.0, big[99][0])
+
+	small := make([][2]float64, 30)
+	for i := 0; i < 4; i++ {
+		n, ok = r2.Stream(small)
+		assert.True(t, ok)
+		assert.Equal(t, 30, n)
+		assert.Equal(t, float64(i*30), small[0][0])
+	}
+	// Samples consumed by all readers are dropped
+	assert.Equal(t, 120, f.end())
+	assert.Equal(t, 100, f.start)
+}
+
+// Readers wait for the samples to be pushed
+func TestFanOut_Blocking(t *testing.T) {
+	f := newFanOut(1000)
+	r := f.newReader("r")
+	src := &test_utils.Ramp{}
+	go func() {
+		for i := 0; i < 10; i++ {
+			time.Sleep(5 * time.Millisecond)
+			f.push(test_utils.Pull(src, 10))
+		}
+		f.close()
+	}()
+	samples := make([][2]float64, 60)
+	n, ok := r.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 60, n)
+	assert.Equal(t, 59.0, samples[59][0])
+	// Once closed, the remaining samples are still read
+	n, ok = r.Stream(samples)
+	assert.True(t, ok)
+	assert.Equal(t, 40, n)
+	_, ok = r.Stream(samples)
+	assert.False(t, ok)
+}
+
+// A stalled reader is detached instead of holding back the others
+// A blocking fan-out holds the pusher back instead of detaching a slow reader
+func TestFanOut_Backpressure(t *testing.T) {
+	f := newFanOut(100)
+	f.blocking = true
+	r := f.newReader("r")
+	src := &test_utils.Ramp{}
+	pushed := make(chan int, 10)
+	go func() {
+		for i := 0; i < 5; i++ {
+			f.push(test_utils.Pull(src, 50))
+			pushed <- i
+		}
+		f.close()
+	}()
+	<-pushed
+	<-pushed
+	select {
+	case <-pushed:
+		assert.Fail(t, "the pusher should wait for the reader")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Reading more than can be buffered returns what is available, so that the pusher can go on
+	var got [][2]float64
+	assert.NoError(t, drainInto(&got, r))
+	assert.Len(t, got, 250)
+	for i, sample := range got {
+		assert.Equal(t, float64(i), sample[0])
+	}
+}
+
+func TestFanOut_DetachLaggingReader(t *testing.T) {
+	f := newFanOut(100)
+	stalled, active := f.newReader("stalled"), f.newReader("active")
+	src := &test_utils.Ramp{}
+	samples := make([][2]float64, 60)
+	for i := 0; i < 2; i++ {
+		f.push(test_utils.Pull(src, 60))
+		_, ok := active.Stream(samples)
+		assert.True(t, ok)
+	}
+	n, ok := stalled.Stream(samples)
+	assert.False(t, ok)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, 120, f.start)
+
+	active.Detach()
+	_, ok = active.Stream(samples)
+	assert.False(t, ok)
+}
@@ -0,0 +1,155 @@
+package recorder
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/faiface/beep"
+	"google.golang.org/protobuf/encoding/protojson"
+	"io"
+	pb "live-audio-mixer/proto"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Journal records every event applied to a mix, along with the position in the mix at which it was applied.
+// It is written as JSON lines: a header holding the sample rate of the mix, one line per event,
+// and a last line marking the end of the mix
+type Journal struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// JournalEntry is an event, along with the position in the mix (in samples) at which it was applied
+type JournalEntry struct {
+	Pos   int
+	Event *pb.Event
+}
+
+// Timeline is the content of a journal
+type Timeline struct {
+	// Sample rate positions are expressed in
+	SampleRate beep.SampleRate
+	Entries    []JournalEntry
+	// Position of the end of the mix. For an incomplete journal, this is the position of the last event
+	End int
+	// Whether the end of the mix has been recorded
+	Complete bool
+}
+
+// NewTimeline builds a timeline from events positioned on the record timeline, for a mix of the given duration.
+// A zero duration ends the mix with the last event
+func NewTimeline(events []*pb.Event, sampleRate beep.SampleRate, duration time.Duration) (*Timeline, error) {
+	if duration < 0 {
+		return nil, fmt.Errorf("invalid duration %v", duration)
+	}
+	timeline := &Timeline{SampleRate: sampleRate, End: sampleRate.N(duration), Complete: true}
+	for i, evt := range events {
+		at, ok := evt.ApplyAt.(*pb.Event_AtRecordMs)
+		if !ok {
+			return nil, fmt.Errorf("event %d must be positioned with atRecordMs", i)
+		}
+		if at.AtRecordMs < 0 {
+			return nil, fmt.Errorf("event %d is positioned before the start of the record", i)
+		}
+		pos := sampleRate.N(time.Duration(at.AtRecordMs) * time.Millisecond)
+		timeline.Entries = append(timeline.Entries, JournalEntry{Pos: pos, Event: evt})
+		if duration == 0 {
+			timeline.End = max(timeline.End, pos)
+		}
+	}
+	// Events at the same position are applied in the order they were given
+	sort.SliceStable(timeline.Entries, func(i, j int) bool {
+		return timeline.Entries[i].Pos < timeline.Entries[j].Pos
+	})
+	return timeline, nil
+}
+
+type journalLine struct {
+	SampleRate beep.SampleRate `json:"sampleRate,omitempty"`
+	Pos        int             `json:"pos"`
+	Event      json.RawMessage `json:"event,omitempty"`
+	End        bool            `json:"end,omitempty"`
+}
+
+// NewJournal starts a journal for a mix made at the given sample rate
+func NewJournal(w io.Writer, sampleRate beep.SampleRate) (*Journal, error) {
+	j := &Journal{w: w}
+	return j, j.write(journalLine{SampleRate: sampleRate})
+}
+
+// Record adds an event to the journal
+func (j *Journal) Record(pos int, evt *pb.Event) error {
+	raw, err := protojson.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	return j.write(journalLine{Pos: pos, Event: raw})
+}
+
+// End marks the end of the mix
+func (j *Journal) End(pos int) error {
+	return j.write(journalLine{Pos: pos, End: true})
+}
+
+func (j *Journal) write(line journalLine) error {
+	raw, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.w.Write(append(raw, '\n'))
+	return err
+}
+
+// ReadJournal parses a journal. As a journal may come from a recording that went wrong,
+// an unreadable line at the end of the journal is ignored
+func ReadJournal(r io.Reader) (*Timeline, error) {
+	scanner := bufio.NewScanner(r)
+	// Events are small, but a line must still be able to hold one whatever its content
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	timeline := &Timeline{}
+	var broken error
+	for n := 1; scanner.Scan(); n++ {
+		if broken != nil {
+			return nil, broken
+		}
+		var line journalLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			broken = fmt.Errorf("journal line %d: %w", n, err)
+			continue
+		}
+		switch {
+		case n == 1:
+			if line.SampleRate <= 0 {
+				return nil, fmt.Errorf("journal line 1: missing sample rate")
+			}
+			timeline.SampleRate = line.SampleRate
+		case line.End:
+			timeline.End = line.Pos
+			timeline.Complete = true
+			return timeline, nil
+		default:
+			evt := &pb.Event{}
+			if err := protojson.Unmarshal(line.Event, evt); err != nil {
+				broken = fmt.Errorf("journal line %d: %w", n, err)
+				continue
+			}
+			timeline.Entries = append(timeline.Entries, JournalEntry{Pos: line.Pos, Event: evt})
+			timeline.End = max(timeline.End, line.Pos)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if timeline.SampleRate == 0 {
+		return nil, fmt.Errorf("empty journal")
+	}
+	if broken != nil {
+		slog.Warn(fmt.Sprintf("[Recorder] :: Ignoring the last line of the journal : %v", broken))
+	}
+	return timeline, nil
+}
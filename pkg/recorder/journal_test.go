@@ -0,0 +1,84 @@
+package recorder
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	pb "live-audio-mixer/proto"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJournal_RoundTrip(t *testing.T) {
+	buf := &bytes.Buffer{}
+	j, err := NewJournal(buf, 44100)
+	assert.NoError(t, err)
+	events := []*pb.Event{
+		{Type: pb.EventType_PLAY, AssetUrl: "http://asset", TrackId: "a", VolumeDeltaDb: -3},
+		{Type: pb.EventType_STOP, TrackId: "a", FadeDurationMs: 500},
+	}
+	assert.NoError(t, j.Record(100, events[0]))
+	assert.NoError(t, j.Record(4410, events[1]))
+	assert.NoError(t, j.End(44100))
+
+	timeline, err := ReadJournal(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 44100, int(timeline.SampleRate))
+	assert.True(t, timeline.Complete)
+	assert.Equal(t, 44100, timeline.End)
+	assert.Len(t, timeline.Entries, 2)
+	for i, entry := range timeline.Entries {
+		assert.True(t, proto.Equal(events[i], entry.Event))
+	}
+	assert.Equal(t, 4410, timeline.Entries[1].Pos)
+}
+
+func TestJournal_Incomplete(t *testing.T) {
+	buf := &bytes.Buffer{}
+	j, err := NewJournal(buf, 48000)
+	assert.NoError(t, err)
+	assert.NoError(t, j.Record(960, &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "http://asset"}))
+	// The recording crashed while writing an event
+	buf.WriteString(`{"pos":1920,"event":{"type":`)
+
+	timeline, err := ReadJournal(buf)
+	assert.NoError(t, err)
+	assert.False(t, timeline.Complete)
+	assert.Len(t, timeline.Entries, 1)
+	assert.Equal(t, 960, timeline.End)
+
+	// Only the last line may be broken
+	_, err = ReadJournal(strings.NewReader("{\"sampleRate\":48000,\"pos\":0}\n{\"pos\":\n{\"pos\":10,\"end\":true}\n"))
+	assert.Error(t, err)
+	_, err = ReadJournal(strings.NewReader(""))
+	assert.Error(t, err)
+}
+
+func TestNewTimeline(t *testing.T) {
+	events := []*pb.Event{
+		{Type: pb.EventType_STOP, TrackId: "a", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 2000}},
+		{Type: pb.EventType_PLAY, TrackId: "a", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 500}},
+		{Type: pb.EventType_VOLUME, TrackId: "a", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 500}},
+	}
+	timeline, err := NewTimeline(events, 48000, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 96000, timeline.End)
+	assert.True(t, timeline.Complete)
+	// Sorted by position, keeping the given order at the same position
+	for i, evt := range []*pb.Event{events[1], events[2], events[0]} {
+		assert.Same(t, evt, timeline.Entries[i].Event)
+	}
+	assert.Equal(t, 24000, timeline.Entries[0].Pos)
+
+	timeline, err = NewTimeline(events, 48000, 10*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 480000, timeline.End)
+
+	_, err = NewTimeline([]*pb.Event{{Type: pb.EventType_PLAY}}, 48000, 0)
+	assert.Error(t, err)
+	_, err = NewTimeline([]*pb.Event{{Type: pb.EventType_PLAY, ApplyAt: &pb.Event_AtUnixMs{AtUnixMs: 1000}}}, 48000, 0)
+	assert.Error(t, err)
+	_, err = NewTimeline(events, 48000, -time.Second)
+	assert.Error(t, err)
+}
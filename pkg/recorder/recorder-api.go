@@ -1,21 +1,152 @@
 package recorder
 
 import (
+	"errors"
 	"github.com/faiface/beep"
 	"io"
 	disc_jockey "live-audio-mixer/internal/disc-jockey"
+	"live-audio-mixer/internal/loudness"
+	master_bus "live-audio-mixer/internal/master-bus"
 	pb "live-audio-mixer/proto"
 	"os"
 	"sync"
 	"time"
 )
 
+const (
+	// Maximum delay a sink can accumulate before being detached from the mix
+	sinkMaxLag = 10 * time.Second
+	// Delay behind the wall clock after which the mix is reported as late
+	maxDrift = 50 * time.Millisecond
+)
+
+// Limits on the assets preloaded and not played yet. Each of them holds a stream open, with its transcoder
+const (
+	maxPreloadsPerAsset = 4
+	maxPreloads         = 16
+	// Preloaded streams not played within this duration are released
+	DefaultPreloadTTL = 5 * time.Minute
+)
+
+const (
+	// Only the beginning of longer assets is measured, so that endless streams can be normalized as well
+	maxMeasuredLength = 10 * time.Minute
+	// Duration of the ramp bringing a track to its target loudness, once measured while it plays
+	normalizationRamp = 1 * time.Second
+	// The asset of a scheduled PLAY event is opened this long before the event is applied
+	scheduleLead = 10 * time.Second
+)
+
+// Bounds and default value of the duration of the blocks pulled by the mixing clock
+const (
+	MinBlock     = 1 * time.Millisecond
+	MaxBlock     = 100 * time.Millisecond
+	DefaultBlock = 10 * time.Millisecond
+)
+
+var (
+	// ErrTrackNotFound is returned by events targeting a track that is not playing
+	ErrTrackNotFound = disc_jockey.ErrTrackNotFound
+	// ErrTrackExists is returned by PLAY events using the id of a track already playing
+	ErrTrackExists = disc_jockey.ErrTrackExists
+	// ErrLoad is returned by PLAY events whose asset couldn't be loaded
+	ErrLoad = errors.New("asset couldn't be loaded")
+	// ErrInvalidEvent is returned for events that can't be applied whatever the state of the mix
+	ErrInvalidEvent = errors.New("invalid event")
+	// ErrTooManyPreloads is returned by PRELOAD events once too many assets are preloaded and not played yet
+	ErrTooManyPreloads = errors.New("too many assets preloaded")
+	// ErrPaused is returned when pausing a mix already paused
+	ErrPaused = errors.New("mix already paused")
+	// ErrNotPaused is returned when resuming a mix that is not paused
+	ErrNotPaused = errors.New("mix not paused")
+)
+
+// Roles of the tracks in the mixtable, by role of the PLAY event
+var roles = map[pb.TrackRole]disc_jockey.Role{
+	pb.TrackRole_ROLE_NONE:   disc_jockey.RoleNone,
+	pb.TrackRole_ROLE_MUSIC:  disc_jockey.RoleMusic,
+	pb.TrackRole_ROLE_EFFECT: disc_jockey.RoleEffect,
+	pb.TrackRole_ROLE_VOICE:  disc_jockey.RoleVoice,
+}
+
+// DefaultFormat is the format of the mix when none is specified: 48kHz, stereo, 16 bits
+var DefaultFormat = beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
+
 type Recorder struct {
-	dj    *disc_jockey.DiscJockey
-	src   StreamingSrc
-	state map[string]*pb.Event
-	sink  Sink
-	mu    sync.Mutex
+	dj *disc_jockey.DiscJockey
+	// Master gain, mute and limiter, applied to the output of the mixtable
+	bus *master_bus.Bus
+	src StreamingSrc
+	// PLAY event of each track currently in the mixtable, by track ID
+	state  map[string]*pb.Event
+	format beep.Format
+	// The mix, shared between all sinks
+	mix *fanOut
+	// Drives the mix at the pace of the wall clock
+	clock *clock
+	// Records every event applied to the mix, if any
+	journal *Journal
+	// Called with every notification about the tracks of the mix, if any
+	onStatus func(status *pb.Status)
+	// Loudness of the assets, for PLAY events with a target loudness
+	loudness *loudness.Cache
+	// Streams opened by PRELOAD events, by asset URL, waiting to be played
+	preloaded map[string][]*preloadedStream
+	// Streams opened ahead of scheduled PLAY events, by event, waiting for the event to be applied
+	scheduled map[*pb.Event]*preloadedStream
+	// Number of streams being preloaded, by asset URL
+	preloading map[string]int
+	// Duration preloaded streams are kept for
+	preloadTTL time.Duration
+	// Set once the recorder is stopped, preloads finishing afterwards are dropped
+	stopped bool
+	sinks   map[string]*Sink
+	mu      sync.Mutex
+
+	// Scheduled events applied late, in order, because the asset of a PLAY event wasn't open in time
+	late   []func()
+	lateMu sync.Mutex
+}
+
+type RecorderOpt struct {
+	// Duration of the blocks pulled by the mixing clock, 0 meaning DefaultBlock.
+	// Events take effect on block boundaries, smaller blocks reduce their latency at the cost of more overhead
+	Block time.Duration
+	// When set, every event applied to the mix is recorded in this journal
+	Journal *Journal
+	// When set, called whenever a track starts, ends, loops, or an event fails.
+	// It may be called while the mix is being rendered, and must not block
+	OnStatus func(status *pb.Status)
+	// Duration preloaded streams are kept for if not played, 0 meaning DefaultPreloadTTL
+	PreloadTTL time.Duration
+	// Loudness of the assets already measured. It can be shared between recorders,
+	// so that each asset is only measured once. When nil, the recorder uses a cache of its own
+	Loudness *loudness.Cache
+}
+
+// TrackState is the state of a track of the mix
+type TrackState struct {
+	disc_jockey.TrackInfo
+	// Empty for a track being faded out after a STOP event
+	AssetUrl string
+	Loop     bool
+}
+
+// MasterState is the state of the processing applied to the whole mix
+type MasterState struct {
+	GainDb  float64
+	Muted   bool
+	Limiter master_bus.LimiterOpt
+	// How music tracks are ducked by effect and voice tracks
+	Ducking disc_jockey.DuckingOpt
+}
+
+// preloadedStream is a stream of an asset opened ahead of time
+type preloadedStream struct {
+	stream beep.StreamSeekCloser
+	format beep.Format
+	// Releases the stream if it is not played in time
+	expiry *time.Timer
 }
 
 type EncodeFn func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) (err error)
@@ -23,8 +154,20 @@ type Sink struct {
 	fn   func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) (err error)
 	stop chan os.Signal
 	ack  chan error
+	// Reading end of the mix for this sink
+	reader *fanOutReader
 }
 
 type StreamingSrc interface {
 	GetStream(string, time.Duration) (beep.StreamSeekCloser, beep.Format, error)
 }
+
+// VersionedSrc is a StreamingSrc telling the version of its assets, so that the loudness of an asset replaced at the
+// same URL is measured again. Without it, the loudness of an asset is kept as long as its URL
+type VersionedSrc interface {
+	StreamingSrc
+	// Version returns the version of an asset, fetching it if needed
+	Version(url string) (string, error)
+	// KnownVersion returns the version of an asset if it is known without fetching it
+	KnownVersion(url string) (string, bool)
+}
@@ -1,126 +1,951 @@
 package recorder
 
 import (
+	"errors"
 	"fmt"
 	"github.com/faiface/beep"
+	"google.golang.org/protobuf/proto"
+	"io"
 	disc_jockey "live-audio-mixer/internal/disc-jockey"
+	"live-audio-mixer/internal/loudness"
+	master_bus "live-audio-mixer/internal/master-bus"
 	pb "live-audio-mixer/proto"
 	"log/slog"
+	"math"
 	"os"
+	"sort"
 	"sync"
 	"time"
 )
 
-func NewRecorder(src StreamingSrc, to EncodeFn) *Recorder {
+// NewRecorder creates a new recorder mixing tracks from src. Every sink receives the mix in the given format.
+// src is expected to produce streams matching this format
+// The mix starts right away, at the pace of the wall clock
+func NewRecorder(src StreamingSrc, format beep.Format, opt RecorderOpt) *Recorder {
+	r := newRecorder(src, format, opt)
+	r.clock.start()
+	return r
+}
+
+func newRecorder(src StreamingSrc, format beep.Format, opt RecorderOpt) *Recorder {
+	if opt.Block == 0 {
+		opt.Block = DefaultBlock
+	}
+	if opt.Loudness == nil {
+		opt.Loudness = loudness.NewCache(0)
+	}
+	if opt.PreloadTTL == 0 {
+		opt.PreloadTTL = DefaultPreloadTTL
+	}
+	dj := disc_jockey.NewDiscJockey(format.SampleRate)
+	bus := master_bus.NewBus(dj, format.SampleRate)
+	mix := newFanOut(format.SampleRate.N(sinkMaxLag))
 	return &Recorder{
-		dj:    disc_jockey.NewDiscJockey(),
-		state: map[string]*pb.Event{},
-		src:   src,
-		sink:  Sink{fn: to, stop: make(chan os.Signal, 1), ack: make(chan error, 1)},
-		mu:    sync.Mutex{},
+		dj:         dj,
+		bus:        bus,
+		state:      map[string]*pb.Event{},
+		src:        src,
+		format:     format,
+		mix:        mix,
+		clock:      newClock(bus, format.SampleRate, opt.Block, mix.push),
+		journal:    opt.Journal,
+		onStatus:   opt.OnStatus,
+		loudness:   opt.Loudness,
+		preloaded:  map[string][]*preloadedStream{},
+		scheduled:  map[*pb.Event]*preloadedStream{},
+		preloading: map[string]int{},
+		preloadTTL: opt.PreloadTTL,
+		sinks:      map[string]*Sink{},
+		mu:         sync.Mutex{},
 	}
 }
 
-func (r *Recorder) Start(to *os.File) chan error {
+// Render replays a timeline against src, and encodes the resulting mix with fn. The mix is rendered as fast
+// as the encoder can go, and the function returns once it is over.
+// The format of the mix may differ from the one the timeline was recorded with.
+// The mix is rendered to its end even if some events can't be applied, their errors are then returned
+func Render(src StreamingSrc, format beep.Format, opt RecorderOpt, timeline *Timeline, w io.WriteSeeker, fn EncodeFn) error {
+	opt.Journal = nil
+	r := newRecorder(src, format, opt)
+	r.mix.blocking = true
+	r.clock.offline = true
+	// Positions are converted to the sample rate of the new mix
+	scale := func(pos int) int {
+		return int(int64(pos) * int64(format.SampleRate) / int64(timeline.SampleRate))
+	}
+	r.clock.limit = scale(timeline.End)
+	r.clock.onLimit = r.mix.close
+	// Only the clock appends to it, and it is read once the clock is halted
+	var applyErrs []error
+	for _, entry := range timeline.Entries {
+		evt, pos := entry.Event, scale(entry.Pos)
+		r.clock.schedule(max(pos-r.bus.Latency(), 0), func() {
+			if err := r.apply(evt); err != nil {
+				applyErrs = append(applyErrs, fmt.Errorf("event %v at %v : %w", evt.Type, format.SampleRate.D(pos), err))
+			}
+		})
+	}
+	ack, err := r.AddSink("render", w, fn)
+	if err != nil {
+		return err
+	}
+	r.clock.start()
+	err = <-ack
+	r.Stop()
+	r.dj.CloseAll()
+	return errors.Join(append([]error{err}, applyErrs...)...)
+}
+
+// AddSink starts encoding the mix to a new destination, using the provided encoder.
+// Each sink has its own lifecycle, an error in one sink does not affect the others.
+// The returned channel receives the result of the encoding once the sink is stopped
+func (r *Recorder) AddSink(name string, to io.WriteSeeker, fn EncodeFn) (chan error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sinks[name]; ok {
+		return nil, fmt.Errorf(`sink "%s" already exists`, name)
+	}
+	sink := &Sink{
+		fn:     fn,
+		stop:   make(chan os.Signal, 1),
+		ack:    make(chan error, 1),
+		reader: r.mix.newReader(name),
+	}
+	r.sinks[name] = sink
 	// Starts encoding asynchronously
-	go func(stop chan os.Signal, ack chan error) {
-		format := beep.Format{SampleRate: 48000, NumChannels: 2, Precision: 2}
-		ack <- r.sink.fn(to, r.dj, format, stop)
-	}(r.sink.stop, r.sink.ack)
+	go func(sink *Sink) {
+		err := sink.fn(to, sink.reader, r.format, sink.stop)
+		// Whatever happened, this sink must not hold back the others
+		sink.reader.Detach()
+		if err != nil {
+			slog.Error(fmt.Sprintf("[Recorder] :: Sink %s stopped with an error : %v", name, err))
+		}
+		sink.ack <- err
+	}(sink)
 
-	return r.sink.ack
+	return sink.ack, nil
 }
 
+// RemoveSink stops a single sink, the other ones keep running
+func (r *Recorder) RemoveSink(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sink, ok := r.sinks[name]
+	if !ok {
+		return fmt.Errorf(`sink "%s" not found`, name)
+	}
+	sink.stop <- os.Interrupt
+	delete(r.sinks, name)
+	return nil
+}
+
+// Stop every sink and the mix itself
 func (r *Recorder) Stop() {
+	r.mu.Lock()
+	for name, sink := range r.sinks {
+		sink.stop <- os.Interrupt
+		delete(r.sinks, name)
+	}
+	// Assets preloaded and never played are released
+	r.stopped = true
+	for url, streams := range r.preloaded {
+		for _, preloaded := range streams {
+			preloaded.expiry.Stop()
+			preloaded.stream.Close()
+		}
+		delete(r.preloaded, url)
+	}
+	for play, prepared := range r.scheduled {
+		prepared.stream.Close()
+		delete(r.scheduled, play)
+	}
+	r.mu.Unlock()
+	// The clock may be applying a scheduled event, which requires the lock
+	r.clock.halt()
+	// Sinks waiting for samples are released
+	r.mix.close()
+	if r.journal != nil {
+		// Like the events, the end is recorded on the timeline of the output, delayed by the master bus
+		if err := r.journal.End(r.clock.position() + r.bus.Latency()); err != nil {
+			slog.Error(fmt.Sprintf("[Recorder] :: Error while ending the journal : %v", err))
+		}
+	}
+}
+
+// Drift returns how late the mix is compared to the wall clock
+func (r *Recorder) Drift() time.Duration {
+	return r.clock.Drift()
+}
+
+// Latency returns the delay between the reception of the last event and the moment
+// the first block of mix including it was handed to the sinks
+func (r *Recorder) Latency() time.Duration {
+	return r.clock.Latency()
+}
+
+// Pause freezes the mix: no samples are handed to the sinks, and tracks keep their position until the mix is resumed.
+// Events are still applied, and heard once resumed
+func (r *Recorder) Pause() error {
+	if !r.clock.pause() {
+		return ErrPaused
+	}
+	return nil
+}
+
+// Resume continues a paused mix right where it stopped
+func (r *Recorder) Resume() error {
+	if !r.clock.resume() {
+		return ErrNotPaused
+	}
+	return nil
+}
+
+// Paused returns whether the mix is paused
+func (r *Recorder) Paused() bool {
+	return r.clock.isPaused()
+}
+
+// Started returns the wall-clock time at which the mix started
+func (r *Recorder) Started() time.Time {
+	return r.clock.startTime()
+}
+
+// Elapsed returns the duration of the mix rendered so far
+func (r *Recorder) Elapsed() time.Duration {
+	return r.format.SampleRate.D(r.clock.position())
+}
+
+// Master returns the state of the master bus
+func (r *Recorder) Master() MasterState {
+	return MasterState{GainDb: r.bus.Gain(), Muted: r.bus.Muted(), Limiter: r.bus.Limiter(), Ducking: r.dj.Ducking()}
+}
+
+// Tracks returns the state of every track of the mix, sorted by track ID
+func (r *Recorder) Tracks() []TrackState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tracks []TrackState
+	for id, info := range r.dj.Tracks() {
+		track := TrackState{TrackInfo: info}
+		if evt, ok := r.state[id]; ok {
+			track.AssetUrl = evt.AssetUrl
+			track.Loop = evt.Loop
+		}
+		tracks = append(tracks, track)
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].Id < tracks[j].Id })
+	return tracks
+}
+
+// Update applies an event to the mix, and returns why it couldn't be applied, if so.
+// Events with an apply time are queued until the mix reaches it. For those, only invalid events are reported,
+// errors happening once applied are notified through OnStatus
+func (r *Recorder) Update(evt *pb.Event) error {
+	if _, ok := pb.EventType_name[int32(evt.Type)]; !ok || evt.Type == pb.EventType_UNSPECIFIED {
+		return fmt.Errorf("%w: unknown event type %v", ErrInvalidEvent, evt.Type)
+	}
+	if evt.ApplyAt != nil {
+		r.schedule(evt)
+		return nil
+	}
+	received := time.Now()
+	err := r.apply(evt)
+	r.clock.eventApplied(received)
+	// The event is heard from the next block on, once through the master bus
+	r.record(r.clock.position()+r.bus.Latency(), evt)
+	return err
+}
+
+// schedule queues an event until the mix reaches its apply time
+func (r *Recorder) schedule(evt *pb.Event) {
+	var pos int
+	switch at := evt.ApplyAt.(type) {
+	case *pb.Event_AtRecordMs:
+		pos = r.format.SampleRate.N(time.Duration(at.AtRecordMs) * time.Millisecond)
+	case *pb.Event_AtUnixMs:
+		pos = r.clock.positionAt(time.UnixMilli(at.AtUnixMs))
+	}
+	// The master bus delays the mix, the event is applied ahead of time to be heard at the right position
+	at := max(pos-r.bus.Latency(), 0)
+	var prepared chan struct{}
+	if evt.Type == pb.EventType_PLAY {
+		// Opening and measuring the asset takes a while, the clock only starts it so that the mix doesn't wait
+		prepared = make(chan struct{})
+		r.clock.schedule(max(at-r.format.SampleRate.N(scheduleLead), 0), func() {
+			go r.prepare(evt, prepared)
+		})
+	}
+	inTime := r.clock.schedule(at, func() {
+		r.applyScheduled(evt, pos, prepared)
+	})
+	if !inTime {
+		slog.Warn(fmt.Sprintf("[Recorder] :: Event %s is scheduled at %v, which is already past. Applying it right away", evt.EvtId, r.format.SampleRate.D(pos)))
+	}
+}
+
+// applyScheduled applies a scheduled event, from the clock goroutine. The clock never waits for an asset to open:
+// a PLAY event whose asset isn't open yet, and every event scheduled after it, are handed over to a goroutine
+// applying them in order as soon as possible. Offline, the clock waits instead, so that the render doesn't depend
+// on how long assets take to open
+func (r *Recorder) applyScheduled(evt *pb.Event, pos int, prepared chan struct{}) {
+	apply := func() {
+		if prepared != nil {
+			<-prepared
+		}
+		// Errors are notified, there is no one left to return them to
+		_ = r.apply(evt)
+		r.dropPrepared(evt)
+		r.record(pos, evt)
+	}
+	if r.clock.offline {
+		apply()
+		return
+	}
+	// Only the clock goroutine adds events, the queue can't be filled between the check and the application
+	r.lateMu.Lock()
+	queued := len(r.late) > 0
+	if !queued && (prepared == nil || r.isPrepared(evt, prepared)) {
+		r.lateMu.Unlock()
+		apply()
+		return
+	}
+	r.late = append(r.late, apply)
+	if !queued {
+		slog.Warn(fmt.Sprintf("[Recorder] :: Asset %s of event %s isn't open yet, applying the event as soon as it is", evt.AssetUrl, evt.EvtId))
+		go r.applyLate()
+	}
+	r.lateMu.Unlock()
+}
+
+// applyLate applies the events queued by applyScheduled, in order, until the queue is empty.
+// An event is only removed from the queue once applied, so that the events scheduled meanwhile are queued after it
+func (r *Recorder) applyLate() {
+	for {
+		r.lateMu.Lock()
+		apply := r.late[0]
+		r.lateMu.Unlock()
+		r.mu.Lock()
+		stopped := r.stopped
+		r.mu.Unlock()
+		// The events left are dropped along with the mix
+		if !stopped {
+			apply()
+		}
+		r.lateMu.Lock()
+		r.late = r.late[1:]
+		empty := len(r.late) == 0
+		r.lateMu.Unlock()
+		if empty {
+			return
+		}
+	}
+}
+
+// isPrepared returns whether the asset of a scheduled PLAY event is open, ready to be played
+func (r *Recorder) isPrepared(play *pb.Event, prepared chan struct{}) bool {
+	select {
+	case <-prepared:
+	default:
+		return false
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.sink.stop <- os.Interrupt
+	_, ok := r.scheduled[play]
+	return ok
 }
 
-func (r *Recorder) Update(evt *pb.Event) {
+// prepare opens the asset of a scheduled PLAY event, and measures it if needed, so that applying the event
+// only adds the track. done is closed once the asset is ready
+func (r *Recorder) prepare(play *pb.Event, done chan struct{}) {
+	defer close(done)
+	if play.NormalizeLufs != nil {
+		// The measure is kept, errors are reported when applying the event
+		_, _ = r.normalization(play)
+	}
+	stream, format, err := r.src.GetStream(play.AssetUrl, 0)
+	if err != nil {
+		slog.Warn(fmt.Sprintf("[Recorder] :: Error while opening asset %s ahead of event %s : %v", play.AssetUrl, play.EvtId, err))
+		return
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if r.stopped {
+		stream.Close()
+		return
+	}
+	r.scheduled[play] = &preloadedStream{stream: stream, format: format}
+}
+
+// dropPrepared releases the stream opened for a scheduled PLAY event, if the event didn't play it
+func (r *Recorder) dropPrepared(play *pb.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if prepared, ok := r.scheduled[play]; ok {
+		prepared.stream.Close()
+		delete(r.scheduled, play)
+	}
+}
+
+// record adds an applied event to the journal, if any
+func (r *Recorder) record(pos int, evt *pb.Event) {
+	if r.journal == nil {
+		return
+	}
+	if err := r.journal.Record(pos, evt); err != nil {
+		slog.Error(fmt.Sprintf("[Recorder] :: Error while adding event %v to the journal : %v", evt, err))
+	}
+}
+
+func (r *Recorder) apply(evt *pb.Event) error {
+	id := trackId(evt)
 	var err error
-	r.state[evt.AssetUrl] = evt
+	// Opening an asset takes a while, PLAY and SEEK events only hold the lock once their asset is open,
+	// so that the other events don't wait for it
 	switch evt.Type {
 	case pb.EventType_PLAY:
-		err = r.addTrack(evt.AssetUrl, evt.VolumeDeltaDb, 0)
+		err = r.playTrack(id, evt)
+	case pb.EventType_SEEK:
+		err = r.seekTrack(id, seekPosition(evt))
+	default:
+		err = r.applyLocked(id, evt)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("[Recorder] :: Error while handling event %v : %v", evt, err))
+		// A PLAY event fails when its asset can't be loaded
+		statusType := pb.StatusType_EVENT_ERROR
+		if evt.Type == pb.EventType_PLAY {
+			statusType = pb.StatusType_LOAD_ERROR
+		} else if evt.Type == pb.EventType_PRELOAD {
+			statusType = pb.StatusType_PRELOAD_ERROR
+		}
+		r.notify(&pb.Status{Type: statusType, TrackId: id, AssetUrl: evt.AssetUrl, EvtId: evt.EvtId, Error: err.Error()})
+	}
+	return err
+}
+
+// applyLocked applies the events which don't open any asset, holding the lock
+func (r *Recorder) applyLocked(id string, evt *pb.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var err error
+	switch evt.Type {
 	case pb.EventType_STOP:
-		err = r.removeTrack(evt.AssetUrl)
+		delete(r.state, id)
+		err = r.fadeOutTrack(id, time.Duration(evt.FadeDurationMs)*time.Millisecond)
 	case pb.EventType_PAUSE:
-		err = r.pauseTrack(evt.AssetUrl)
+		err = r.pauseTrack(id)
 	case pb.EventType_RESUME:
-		err = r.resumeTrack(evt.AssetUrl)
+		err = r.resumeTrack(id)
 	case pb.EventType_VOLUME:
-		err = r.changeVolume(evt.AssetUrl, evt.VolumeDeltaDb)
-	case pb.EventType_SEEK:
-		err = r.seekTrack(evt.AssetUrl, evt.VolumeDeltaDb, time.Duration(evt.SeekPositionSec)*time.Second)
-	// This type of event only toggles the loop flag currently, there is no processing required
+		err = r.changeVolume(id, evt.VolumeDeltaDb, evt.VolumeTargetDb, time.Duration(evt.FadeDurationMs)*time.Millisecond)
+	case pb.EventType_MASTER_VOLUME:
+		r.changeMasterVolume(evt.VolumeDeltaDb, evt.VolumeTargetDb, time.Duration(evt.FadeDurationMs)*time.Millisecond)
+	case pb.EventType_MASTER_MUTE, pb.EventType_MASTER_UNMUTE:
+		r.bus.SetMuted(evt.Type == pb.EventType_MASTER_MUTE)
+	case pb.EventType_LIMITER:
+		err = r.setLimiter(evt.Limiter)
+	case pb.EventType_DUCKING:
+		err = r.setDucking(evt.Ducking)
+	case pb.EventType_PRELOAD:
+		if evt.AssetUrl == "" {
+			err = fmt.Errorf("%w: missing asset URL", ErrInvalidEvent)
+			break
+		}
+		err = r.reservePreload(evt.AssetUrl)
+		if err == nil {
+			go r.preload(evt)
+		}
+	// This type of event only toggles the loop flag currently
 	case pb.EventType_OTHER:
 		slog.Info(fmt.Sprintf("[Recorder] :: Received OTHER event %v", evt))
+		if track, ok := r.state[id]; ok {
+			track.Loop = evt.Loop
+			err = r.dj.SetLooping(id, evt.Loop)
+		}
 	default:
-		slog.Warn(fmt.Sprintf("[Recorder] :: Unknown event type %v", evt.Type))
+		err = fmt.Errorf("%w: unknown event type %v", ErrInvalidEvent, evt.Type)
 	}
-	if err != nil {
-		slog.Error(fmt.Sprintf("[Recorder] :: Error while handling event %v : %v", evt, err))
+	return err
+}
+
+// notify sends a notification, stamped with the current position in the mix
+func (r *Recorder) notify(status *pb.Status) {
+	if r.onStatus == nil {
+		return
 	}
+	status.PositionMs = r.format.SampleRate.D(r.clock.position()).Milliseconds()
+	r.onStatus(status)
 }
 
-func (r *Recorder) loop(url string) error {
-	lastEvt := r.state[url]
-	if lastEvt.Loop {
-		err := r.addTrack(url, lastEvt.VolumeDeltaDb, 0)
-		if err != nil {
-			return err
-		}
+// trackId returns the identifier of the track instance targeted by an event.
+// For backward compatibility, the asset URL is used when no track ID is provided
+func trackId(evt *pb.Event) string {
+	if evt.TrackId != "" {
+		return evt.TrackId
 	}
-	return nil
+	return evt.AssetUrl
+}
+
+// trackLooped notifies that a track started over
+func (r *Recorder) trackLooped(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	track, ok := r.state[id]
+	// The track has been stopped in the meantime
+	if !ok {
+		return
+	}
+	r.notify(&pb.Status{Type: pb.StatusType_TRACK_LOOPED, TrackId: id, AssetUrl: track.AssetUrl})
+}
+
+// trackEnded forgets a track which played until its end
+func (r *Recorder) trackEnded(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	track, ok := r.state[id]
+	// The track has been stopped in the meantime
+	if !ok {
+		return
+	}
+	delete(r.state, id)
+	r.notify(&pb.Status{Type: pb.StatusType_TRACK_ENDED, TrackId: id, AssetUrl: track.AssetUrl})
 }
 
-// Add a track to the mixtable from its URL
-func (r *Recorder) addTrack(url string, initVolume float64, offset time.Duration) error {
-	stream, format, err := r.src.GetStream(url, offset)
+// playTrack opens the asset of a PLAY event, and adds the track to the mixtable once it is open.
+// Measuring an asset takes a while, the track starts without normalization, and is brought to its target loudness
+// once measured. Offline, the mix waits for the measure so that the render doesn't depend on how long it takes
+func (r *Recorder) playTrack(id string, play *pb.Event) error {
+	role, ok := roles[play.Role]
+	if !ok {
+		return fmt.Errorf("%w: unknown track role %v", ErrInvalidEvent, play.Role)
+	}
+	if play.LoopCount < 0 || play.LoopStartMs < 0 || play.LoopEndMs < 0 || (play.LoopEndMs > 0 && play.LoopEndMs <= play.LoopStartMs) {
+		return fmt.Errorf("%w: invalid loop region %d-%dms or count %d", ErrInvalidEvent, play.LoopStartMs, play.LoopEndMs, play.LoopCount)
+	}
+	if play.NormalizeLufs != nil {
+		if err := checkLoudness(*play.NormalizeLufs); err != nil {
+			return err
+		}
+	}
+	// Checked before opening the asset, so that no preloaded or scheduled stream is used up by an event which fails
+	if r.dj.Has(id) {
+		return fmt.Errorf(`%w: "%s"`, ErrTrackExists, id)
+	}
+	stream, format, err := r.open(play, 0)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoad, err)
+	}
+	// Opening the asset usually tells its version, and thus whether its loudness is known
+	var (
+		normalization float64
+		measuring     bool
+	)
+	if r.clock.offline {
+		normalization, err = r.normalization(play)
+	} else {
+		normalization, measuring, err = r.knownNormalization(play)
+	}
 	if err != nil {
+		_ = stream.Close()
 		return err
 	}
-	err = r.dj.Add(url, stream, format, disc_jockey.AddTrackOpt{
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err = r.addTrack(id, play, stream, format, role, play.VolumeDeltaDb+normalization, time.Duration(play.FadeDurationMs)*time.Millisecond)
+	if err != nil {
+		_ = stream.Close()
+		return err
+	}
+	// The PLAY event defines the track for its whole lifetime
+	r.state[id] = proto.Clone(play).(*pb.Event)
+	r.notify(&pb.Status{Type: pb.StatusType_TRACK_STARTED, TrackId: id, AssetUrl: play.AssetUrl, EvtId: play.EvtId})
+	if measuring {
+		go r.normalizeLater(id, r.state[id])
+	}
+	return nil
+}
+
+// Add a track instance to the mixtable from a stream of its asset
+func (r *Recorder) addTrack(id string, play *pb.Event, stream beep.StreamSeekCloser, format beep.Format, role disc_jockey.Role, initVolume float64, fadeIn time.Duration) error {
+	return r.dj.Add(id, stream, format, disc_jockey.AddTrackOpt{
 		InitVolumeDb: initVolume,
-		OnEnd: func(url string) {
-			err := r.loop(url)
-			if err != nil {
-				slog.Error(fmt.Sprintf("[Recorder] :: Error while looping track %s : %v", url, err))
-			}
+		Role:         role,
+		FadeIn:       fadeIn,
+		Loop: disc_jockey.LoopOpt{
+			Enabled: play.Loop,
+			Count:   int(play.LoopCount),
+			Start:   time.Duration(play.LoopStartMs) * time.Millisecond,
+			End:     time.Duration(play.LoopEndMs) * time.Millisecond,
+			// Streams which can't be sought back are opened again ahead of each iteration
+			Reopen: func() (beep.StreamSeekCloser, error) {
+				stream, _, err := r.src.GetStream(play.AssetUrl, 0)
+				return stream, err
+			},
+			// Offline, the mix waits for new streams so that the render doesn't depend on how long they take to open
+			Wait: r.clock.offline,
 		},
+		OnLoop: r.trackLooped,
+		OnEnd:  r.trackEnded,
 	})
-	if err != nil {
-		return err
+}
+
+// open returns a stream of the asset of a PLAY event from the given offset. The stream opened ahead of the event
+// if it was scheduled, or else a preloaded stream, is used when there is one.
+// The lock must not be held, it is only taken while looking for these streams
+func (r *Recorder) open(play *pb.Event, offset time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	if offset > 0 {
+		return r.src.GetStream(play.AssetUrl, offset)
+	}
+	r.mu.Lock()
+	stream, format, ok := r.takeOpened(play)
+	r.mu.Unlock()
+	if !ok {
+		return r.src.GetStream(play.AssetUrl, 0)
+	}
+	return stream, format, nil
+}
+
+// takeOpened returns the stream opened ahead of a PLAY event if it was scheduled, or else a preloaded stream
+// of its asset, if there is one
+func (r *Recorder) takeOpened(play *pb.Event) (beep.StreamSeekCloser, beep.Format, bool) {
+	url := play.AssetUrl
+	if prepared, ok := r.scheduled[play]; ok {
+		delete(r.scheduled, play)
+		return prepared.stream, prepared.format, true
+	}
+	streams := r.preloaded[url]
+	if len(streams) == 0 {
+		return nil, beep.Format{}, false
+	}
+	if len(streams) == 1 {
+		delete(r.preloaded, url)
+	} else {
+		r.preloaded[url] = streams[1:]
+	}
+	streams[0].expiry.Stop()
+	return streams[0].stream, streams[0].format, true
+}
+
+// reservePreload counts a stream about to be preloaded, unless the limits on preloaded streams are reached
+func (r *Recorder) reservePreload(url string) error {
+	total := 0
+	for _, n := range r.preloading {
+		total += n
+	}
+	for _, streams := range r.preloaded {
+		total += len(streams)
 	}
+	if r.preloading[url]+len(r.preloaded[url]) >= maxPreloadsPerAsset || total >= maxPreloads {
+		return fmt.Errorf("%w: at most %d per asset and %d in total", ErrTooManyPreloads, maxPreloadsPerAsset, maxPreloads)
+	}
+	r.preloading[url]++
 	return nil
 }
 
+// preload opens a stream of an asset ahead of time, for the next PLAY event of this asset.
+// When the event has a target loudness, the asset is measured as well
+func (r *Recorder) preload(evt *pb.Event) {
+	preloading := time.Now()
+	_, err := r.normalization(evt)
+	var (
+		stream beep.StreamSeekCloser
+		format beep.Format
+	)
+	if err == nil {
+		stream, format, err = r.src.GetStream(evt.AssetUrl, 0)
+		if err != nil {
+			err = fmt.Errorf("%w: %w", ErrLoad, err)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.preloading[evt.AssetUrl]--; r.preloading[evt.AssetUrl] == 0 {
+		delete(r.preloading, evt.AssetUrl)
+	}
+	if err != nil {
+		slog.Error(fmt.Sprintf("[Recorder] :: Error while preloading asset %s : %v", evt.AssetUrl, err))
+		r.notify(&pb.Status{Type: pb.StatusType_PRELOAD_ERROR, AssetUrl: evt.AssetUrl, EvtId: evt.EvtId, Error: err.Error()})
+		return
+	}
+	if r.stopped {
+		stream.Close()
+		return
+	}
+	preloaded := &preloadedStream{stream: stream, format: format}
+	preloaded.expiry = time.AfterFunc(r.preloadTTL, func() { r.expirePreload(evt.AssetUrl, preloaded) })
+	r.preloaded[evt.AssetUrl] = append(r.preloaded[evt.AssetUrl], preloaded)
+	slog.Info(fmt.Sprintf("[Recorder] :: Asset %s preloaded in %v", evt.AssetUrl, time.Since(preloading)))
+	r.notify(&pb.Status{Type: pb.StatusType_PRELOAD_FINISHED, AssetUrl: evt.AssetUrl, EvtId: evt.EvtId})
+}
+
+// expirePreload releases a preloaded stream which hasn't been played in time
+func (r *Recorder) expirePreload(url string, preloaded *preloadedStream) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	streams := r.preloaded[url]
+	for i, candidate := range streams {
+		// The stream may have been played or released meanwhile
+		if candidate != preloaded {
+			continue
+		}
+		if len(streams) == 1 {
+			delete(r.preloaded, url)
+		} else {
+			r.preloaded[url] = append(streams[:i:i], streams[i+1:]...)
+		}
+		preloaded.stream.Close()
+		slog.Info(fmt.Sprintf("[Recorder] :: Asset %s preloaded but not played within %v, releasing it", url, r.preloadTTL))
+		return
+	}
+}
+
 // Remove a track from the mixtable
-func (r *Recorder) removeTrack(url string) error {
-	return r.dj.Remove(url)
+func (r *Recorder) removeTrack(id string) error {
+	return r.dj.Remove(id)
+}
+
+// Remove a track from the mixtable after fading it out
+func (r *Recorder) fadeOutTrack(id string, duration time.Duration) error {
+	return r.dj.FadeOut(id, duration)
 }
 
 // Pause a track
-func (r *Recorder) pauseTrack(url string) error {
-	return r.dj.SetPaused(url, true)
+func (r *Recorder) pauseTrack(id string) error {
+	return r.dj.SetPaused(id, true)
 }
 
 // Resume a track
-func (r *Recorder) resumeTrack(url string) error {
-	return r.dj.SetPaused(url, false)
+func (r *Recorder) resumeTrack(id string) error {
+	return r.dj.SetPaused(id, false)
+}
+
+// Change the volume of a track, either relatively to its current volume or to an absolute target.
+// With a non-zero duration, the volume ramps to its new level instead of stepping
+func (r *Recorder) changeVolume(id string, volumeDeltaDb float64, targetDb *float64, duration time.Duration) error {
+	if targetDb != nil {
+		return r.dj.RampVolume(id, *targetDb, duration)
+	}
+	if duration <= 0 {
+		return r.dj.ChangeVolume(id, volumeDeltaDb)
+	}
+	currentDb, err := r.dj.GetVolume(id)
+	if err != nil {
+		return err
+	}
+	return r.dj.RampVolume(id, currentDb+volumeDeltaDb, duration)
 }
 
-func (r *Recorder) changeVolume(url string, volumeDeltaDb float64) error {
-	return r.dj.ChangeVolume(url, volumeDeltaDb)
+// Change the master gain, either relatively to its current level or to an absolute target
+func (r *Recorder) changeMasterVolume(volumeDeltaDb float64, targetDb *float64, duration time.Duration) {
+	if targetDb != nil {
+		r.bus.SetGain(*targetDb, duration)
+		return
+	}
+	r.bus.SetGain(r.bus.Gain()+volumeDeltaDb, duration)
 }
 
-func (r *Recorder) seekTrack(url string, initVolume float64, offset time.Duration) error {
-	err := r.removeTrack(url)
-	if err != nil {
+func (r *Recorder) setLimiter(settings *pb.LimiterSettings) error {
+	if settings == nil {
+		return fmt.Errorf("%w: missing limiter settings", ErrInvalidEvent)
+	}
+	if settings.CeilingDb > 0 || settings.ReleaseMs < 0 {
+		return fmt.Errorf("%w: invalid limiter settings %v", ErrInvalidEvent, settings)
+	}
+	opt := master_bus.LimiterOpt{
+		Enabled:   settings.Enabled,
+		CeilingDb: settings.CeilingDb,
+		Release:   time.Duration(settings.ReleaseMs) * time.Millisecond,
+	}
+	if opt.Release == 0 {
+		opt.Release = master_bus.DefaultLimiter.Release
+	}
+	r.bus.SetLimiter(opt)
+	return nil
+}
+
+func (r *Recorder) setDucking(settings *pb.DuckingSettings) error {
+	if settings == nil {
+		return fmt.Errorf("%w: missing ducking settings", ErrInvalidEvent)
+	}
+	if settings.ThresholdDb > 0 || settings.AttenuationDb > 0 || settings.AttackMs < 0 || settings.ReleaseMs < 0 {
+		return fmt.Errorf("%w: invalid ducking settings %v", ErrInvalidEvent, settings)
+	}
+	opt := disc_jockey.DuckingOpt{
+		Enabled:       settings.Enabled,
+		ThresholdDb:   settings.ThresholdDb,
+		AttenuationDb: settings.AttenuationDb,
+		Attack:        time.Duration(settings.AttackMs) * time.Millisecond,
+		Release:       time.Duration(settings.ReleaseMs) * time.Millisecond,
+	}
+	if opt.Attack == 0 {
+		opt.Attack = disc_jockey.DefaultDucking.Attack
+	}
+	if opt.Release == 0 {
+		opt.Release = disc_jockey.DefaultDucking.Release
+	}
+	r.dj.SetDucking(opt)
+	return nil
+}
+
+// seekTrack moves a track to a new position. Tracks which can't be sought go on from a new stream of their asset,
+// opened without holding the lock
+func (r *Recorder) seekTrack(id string, offset time.Duration) error {
+	r.mu.Lock()
+	track, ok := r.state[id]
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf(`%w: "%s"`, ErrTrackNotFound, id)
+	}
+	if offset < 0 {
+		return fmt.Errorf("%w: negative seek position %v", ErrInvalidEvent, offset)
+	}
+	err := r.dj.Seek(id, offset)
+	if err == nil || errors.Is(err, ErrTrackNotFound) {
 		return err
 	}
-	return r.addTrack(url, initVolume, offset)
+	// Streams being transcoded can't be sought, the asset is transcoded again from the offset.
+	// The track goes on from the new stream with the volume, fades, pause and loop state it had
+	slog.Debug(fmt.Sprintf("[Recorder] :: Track %s can't be sought in place, opening it again at %v : %v", id, offset, err))
+	stream, _, err := r.open(track, offset)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrLoad, err)
+	}
+	err = r.dj.Replace(id, stream, offset)
+	if err != nil {
+		_ = stream.Close()
+	}
+	return err
+}
+
+// seekPosition returns the position a SEEK event moves its track to
+func seekPosition(evt *pb.Event) time.Duration {
+	if evt.SeekPositionMs != nil {
+		return time.Duration(*evt.SeekPositionMs) * time.Millisecond
+	}
+	return time.Duration(evt.SeekPositionSec) * time.Second
+}
+
+// normalization returns the gain bringing the asset of an event to its target loudness, if any.
+// The loudness of each asset is measured the first time it is played or preloaded, by decoding it, which takes a while
+func (r *Recorder) normalization(evt *pb.Event) (float64, error) {
+	if evt.NormalizeLufs == nil {
+		return 0, nil
+	}
+	if err := checkLoudness(*evt.NormalizeLufs); err != nil {
+		return 0, err
+	}
+	key, err := r.loudnessKey(evt.AssetUrl)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrLoad, err)
+	}
+	return r.normalizationOf(evt, key)
+}
+
+// normalizationOf returns the gain bringing the asset of an event to its target loudness, the loudness of the asset
+// being cached under key
+func (r *Recorder) normalizationOf(evt *pb.Event, key string) (float64, error) {
+	lufs, err := r.loudness.Get(key, func() (float64, error) {
+		return r.measureLoudness(evt.AssetUrl)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrLoad, err)
+	}
+	// A silent asset can't be brought to any loudness, it is played as is
+	if math.IsInf(lufs, -1) {
+		slog.Warn(fmt.Sprintf("[Recorder] :: Asset %s is silent, it can't be normalized", evt.AssetUrl))
+		return 0, nil
+	}
+	return *evt.NormalizeLufs - lufs, nil
+}
+
+// knownNormalization returns the gain bringing the asset of an event to its target loudness, if the asset has
+// already been measured. Otherwise, measuring is true and the asset must be measured with normalization.
+// Neither the version of the asset nor its loudness are fetched, so that the clock goroutine never waits for them
+func (r *Recorder) knownNormalization(evt *pb.Event) (gain float64, measuring bool, err error) {
+	if evt.NormalizeLufs == nil {
+		return 0, false, nil
+	}
+	if err := checkLoudness(*evt.NormalizeLufs); err != nil {
+		return 0, false, err
+	}
+	if key, ok := r.knownLoudnessKey(evt.AssetUrl); ok {
+		if _, known := r.loudness.Lookup(key); known {
+			gain, err = r.normalizationOf(evt, key)
+			return gain, false, err
+		}
+	}
+	return 0, true, nil
+}
+
+// loudnessKey returns the key the loudness of an asset is cached under, which changes along with the version
+// of the asset when the source tells it
+func (r *Recorder) loudnessKey(url string) (string, error) {
+	src, ok := r.src.(VersionedSrc)
+	if !ok {
+		return url, nil
+	}
+	version, err := src.Version(url)
+	if err != nil {
+		return "", err
+	}
+	return url + "@" + version, nil
+}
+
+// knownLoudnessKey returns the key the loudness of an asset is cached under, if the version of the asset is known
+// without fetching it
+func (r *Recorder) knownLoudnessKey(url string) (string, bool) {
+	src, ok := r.src.(VersionedSrc)
+	if !ok {
+		return url, true
+	}
+	version, ok := src.KnownVersion(url)
+	return url + "@" + version, ok
+}
+
+// normalizeLater measures the asset of a track started before its loudness was known,
+// and then ramps the track to its target loudness
+func (r *Recorder) normalizeLater(id string, play *pb.Event) {
+	gain, err := r.normalization(play)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		slog.Error(fmt.Sprintf("[Recorder] :: Error while measuring asset %s, track %s is played without normalization : %v", play.AssetUrl, id, err))
+		return
+	}
+	// The track may have been stopped, or replaced by another one, in the meantime
+	if r.stopped || r.state[id] != play || gain == 0 {
+		return
+	}
+	if err := r.changeVolume(id, gain, nil, normalizationRamp); err != nil {
+		slog.Warn(fmt.Sprintf("[Recorder] :: Error while normalizing track %s : %v", id, err))
+	}
+}
+
+// checkLoudness checks that a target loudness can be reached
+func checkLoudness(target float64) error {
+	if target > 0 || math.IsNaN(target) {
+		return fmt.Errorf("%w: invalid target loudness %v LUFS", ErrInvalidEvent, target)
+	}
+	return nil
+}
+
+// measureLoudness reads an asset and returns its integrated loudness, in LUFS. Only the first maxMeasuredLength
+// of the asset are measured
+func (r *Recorder) measureLoudness(url string) (float64, error) {
+	measuring := time.Now()
+	stream, format, err := r.src.GetStream(url, 0)
+	if err != nil {
+		return 0, err
+	}
+	defer stream.Close()
+	sampleRate := format.SampleRate
+	if sampleRate == 0 {
+		sampleRate = r.format.SampleRate
+	}
+	lufs, err := loudness.Measure(beep.Take(sampleRate.N(maxMeasuredLength), stream), sampleRate)
+	if err != nil {
+		return 0, err
+	}
+	slog.Info(fmt.Sprintf("[Recorder] :: Asset %s measured at %.1f LUFS in %v", url, lufs, time.Since(measuring)))
+	return lufs, nil
 }
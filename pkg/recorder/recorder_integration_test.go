@@ -33,7 +33,7 @@ func setup(t *testing.T) *testSetup {
 	file, err := os.Create(fLoc)
 	assert.NoError(t, err)
 	return &testSetup{
-		Rec:  NewRecorder(stream_handler.NewHandler(), rt_encoder.FFEncode),
+		Rec:  NewRecorder(stream_handler.NewHandler(DefaultFormat), DefaultFormat, RecorderOpt{}),
 		Dir:  path,
 		File: file,
 		destroy: func(t *testing.T) {
@@ -53,7 +53,8 @@ func setup(t *testing.T) *testSetup {
 func TestStartStop(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -77,7 +78,7 @@ func TestStartStop(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
@@ -95,7 +96,8 @@ func TestStartStop(t *testing.T) {
 func TestNoLoop(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -114,7 +116,7 @@ func TestNoLoop(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
@@ -132,7 +134,8 @@ func TestNoLoop(t *testing.T) {
 func TestLoop(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -151,7 +154,7 @@ func TestLoop(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
@@ -169,7 +172,8 @@ func TestLoop(t *testing.T) {
 func TestMultiTrack(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -199,7 +203,7 @@ func TestMultiTrack(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
@@ -218,7 +222,8 @@ func TestMultiTrack(t *testing.T) {
 func TestPauseResume(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -254,7 +259,7 @@ func TestPauseResume(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
@@ -273,7 +278,8 @@ func TestPauseResume(t *testing.T) {
 func TestVolume(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -319,7 +325,7 @@ func TestVolume(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
@@ -338,7 +344,8 @@ func TestVolume(t *testing.T) {
 func TestSeek(t *testing.T) {
 	test := setup(t)
 	defer test.destroy(t)
-	errCh := test.Rec.Start(test.File)
+	errCh, err := test.Rec.AddSink("file", test.File, rt_encoder.FFEncode)
+	assert.NoError(t, err)
 	go func() {
 		time.Sleep(1 * time.Second)
 		test.Rec.Update(&pb.Event{
@@ -373,7 +380,7 @@ func TestSeek(t *testing.T) {
 
 	// To be able to compare the file, we must encode the ogg file to wav
 	wavPath := filepath.Join(test.Dir, "rec.wav")
-	err := test_utils.ToWav(test.File.Name(), wavPath)
+	err = test_utils.ToWav(test.File.Name(), wavPath)
 	assert.NoError(t, err)
 
 	// Then we can test both files
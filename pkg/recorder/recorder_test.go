@@ -5,12 +5,20 @@
 package recorder
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/faiface/beep"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/proto"
 	"io"
+	disc_jockey "live-audio-mixer/internal/disc-jockey"
+	master_bus "live-audio-mixer/internal/master-bus"
+	pb "live-audio-mixer/proto"
+	test_utils "live-audio-mixer/test-utils"
+	"math"
 	"os"
+	"sync"
 	"testing"
 	"time"
 )
@@ -18,8 +26,9 @@ import (
 func TestRecorder_StartCatchingErrors(t *testing.T) {
 	mockEncoder := &mockEncoder{}
 	mockEncoder.On("Encode").Return(fmt.Errorf("Test"))
-	rec := NewRecorder(nil, mockEncoder.Encode)
-	errCh := rec.Start(nil)
+	rec := NewRecorder(nil, DefaultFormat, RecorderOpt{})
+	errCh, err := rec.AddSink("mock", nil, mockEncoder.Encode)
+	assert.NoError(t, err)
 	select {
 	case err := <-errCh:
 		assert.Error(t, err)
@@ -31,8 +40,9 @@ func TestRecorder_StartCatchingErrors(t *testing.T) {
 func TestRecorder_StartAndStop(t *testing.T) {
 	mockEncoder := &mockEncoder{}
 	mockEncoder.On("Encode").Return(nil)
-	rec := NewRecorder(nil, mockEncoder.Encode)
-	errCh := rec.Start(nil)
+	rec := NewRecorder(nil, DefaultFormat, RecorderOpt{})
+	errCh, err := rec.AddSink("mock", nil, mockEncoder.Encode)
+	assert.NoError(t, err)
 	select {
 	case err := <-errCh:
 		assert.NoError(t, err)
@@ -42,6 +52,674 @@ func TestRecorder_StartAndStop(t *testing.T) {
 	rec.Stop()
 }
 
+// A failing sink does not stop the others
+func TestRecorder_MultipleSinks(t *testing.T) {
+	failing := &mockEncoder{}
+	failing.On("Encode").Return(fmt.Errorf("Test"))
+	rec := NewRecorder(nil, DefaultFormat, RecorderOpt{})
+	failCh, err := rec.AddSink("failing", nil, failing.Encode)
+	assert.NoError(t, err)
+	okCh1, err := rec.AddSink("ok1", nil, drainEncode)
+	assert.NoError(t, err)
+	okCh2, err := rec.AddSink("ok2", nil, drainEncode)
+	assert.NoError(t, err)
+	_, err = rec.AddSink("ok2", nil, drainEncode)
+	assert.Error(t, err)
+
+	select {
+	case err := <-failCh:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	// Sinks can also be stopped individually
+	err = rec.RemoveSink("ok1")
+	assert.NoError(t, err)
+	select {
+	case err := <-okCh1:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	rec.Stop()
+	select {
+	case err := <-okCh2:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+}
+
+func TestRecorder_LayerSameAsset(t *testing.T) {
+	rec := NewRecorder(&silenceSrc{}, DefaultFormat, RecorderOpt{})
+	const thunder = "https://example.com/thunder.mp3"
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: thunder, TrackId: "thunder-1"})
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: thunder, TrackId: "thunder-2", VolumeDeltaDb: -6})
+	vol, err := rec.dj.GetVolume("thunder-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, vol)
+	vol, err = rec.dj.GetVolume("thunder-2")
+	assert.NoError(t, err)
+	assert.Equal(t, -6.0, vol)
+
+	// Events target a single instance
+	rec.Update(&pb.Event{Type: pb.EventType_VOLUME, TrackId: "thunder-2", VolumeDeltaDb: -6})
+	vol, err = rec.dj.GetVolume("thunder-2")
+	assert.NoError(t, err)
+	assert.Equal(t, -12.0, vol)
+	rec.Update(&pb.Event{Type: pb.EventType_STOP, TrackId: "thunder-1"})
+	_, err = rec.dj.GetVolume("thunder-1")
+	assert.Error(t, err)
+	_, err = rec.dj.GetVolume("thunder-2")
+	assert.NoError(t, err)
+
+	// Without any track ID, the asset URL is the ID
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: thunder})
+	_, err = rec.dj.GetVolume(thunder)
+	assert.NoError(t, err)
+	rec.Update(&pb.Event{Type: pb.EventType_STOP, AssetUrl: thunder})
+	_, err = rec.dj.GetVolume(thunder)
+	assert.Error(t, err)
+}
+
+// Events take effect at the position in the mix matching the time they were received
+func TestRecorder_EventTiming(t *testing.T) {
+	rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{})
+	firstSound, errCh := firstSoundSink(t, rec)
+	time.Sleep(200 * time.Millisecond)
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone"})
+	select {
+	case pos := <-firstSound:
+		assert.InDelta(t, DefaultFormat.SampleRate.N(200*time.Millisecond), pos, float64(DefaultFormat.SampleRate.N(30*time.Millisecond)))
+		// The event is heard within a couple of blocks
+		assert.Eventually(t, func() bool { return rec.Latency() > 0 }, time.Second, time.Millisecond)
+		assert.Less(t, rec.Latency(), 3*DefaultBlock)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	assert.NoError(t, <-errCh)
+	rec.Stop()
+}
+
+// Scheduled events take effect at the exact sample matching their apply time
+func TestRecorder_ScheduledEvent(t *testing.T) {
+	for _, evt := range []*pb.Event{
+		{Type: pb.EventType_PLAY, AssetUrl: "tone", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 150}},
+		// Not on a block boundary
+		{Type: pb.EventType_PLAY, AssetUrl: "tone", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 123}},
+	} {
+		rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{})
+		firstSound, errCh := firstSoundSink(t, rec)
+		rec.Update(evt)
+		select {
+		case pos := <-firstSound:
+			assert.Equal(t, DefaultFormat.SampleRate.N(time.Duration(evt.GetAtRecordMs())*time.Millisecond), pos)
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "timeout")
+		}
+		assert.NoError(t, <-errCh)
+		rec.Stop()
+	}
+
+	// Wall-clock times are converted to a position in the mix
+	rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{})
+	firstSound, errCh := firstSoundSink(t, rec)
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", ApplyAt: &pb.Event_AtUnixMs{AtUnixMs: time.Now().Add(100 * time.Millisecond).UnixMilli()}})
+	select {
+	case pos := <-firstSound:
+		assert.InDelta(t, DefaultFormat.SampleRate.N(100*time.Millisecond), pos, float64(DefaultFormat.SampleRate.N(10*time.Millisecond)))
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	assert.NoError(t, <-errCh)
+	rec.Stop()
+}
+
+// slowSrc takes a while to open streams, and records the position of the mix each stream was opened at
+type slowSrc struct {
+	StreamingSrc
+	delay    time.Duration
+	position func() int
+	mu       sync.Mutex
+	openedAt []int
+}
+
+func (ss *slowSrc) GetStream(url string, offset time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	ss.mu.Lock()
+	ss.openedAt = append(ss.openedAt, ss.position())
+	ss.mu.Unlock()
+	time.Sleep(ss.delay)
+	return ss.StreamingSrc.GetStream(url, offset)
+}
+
+// The asset of a scheduled PLAY event is opened ahead of time, the mix doesn't wait for it
+func TestRecorder_ScheduledPlayOpensAhead(t *testing.T) {
+	src := &slowSrc{StreamingSrc: &toneSrc{}, delay: 200 * time.Millisecond}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{})
+	src.position = rec.clock.position
+	firstSound, errCh := firstSoundSink(t, rec)
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 500}})
+	select {
+	case pos := <-firstSound:
+		assert.Equal(t, DefaultFormat.SampleRate.N(500*time.Millisecond), pos)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	assert.NoError(t, <-errCh)
+	rec.Stop()
+	src.mu.Lock()
+	defer src.mu.Unlock()
+	assert.Len(t, src.openedAt, 1)
+	assert.Less(t, src.openedAt[0], DefaultFormat.SampleRate.N(300*time.Millisecond))
+}
+
+// The mix goes on while the asset of a scheduled PLAY event is still opening, the event and the ones scheduled
+// after it are applied in order once it is open
+func TestRecorder_ScheduledPlayOpensLate(t *testing.T) {
+	statuses := make(chan *pb.Status, 100)
+	src := &slowSrc{StreamingSrc: &toneSrc{}, delay: 500 * time.Millisecond}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{OnStatus: func(status *pb.Status) { statuses <- status }})
+	defer rec.Stop()
+	src.position = rec.clock.position
+	_, err := rec.AddSink("drain", nil, drainEncode)
+	assert.NoError(t, err)
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 50}})
+	rec.Update(&pb.Event{Type: pb.EventType_VOLUME, AssetUrl: "tone", VolumeDeltaDb: -6, ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 100}})
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Empty(t, rec.Tracks())
+	assert.Greater(t, rec.Elapsed(), 200*time.Millisecond)
+	select {
+	case status := <-statuses:
+		assert.Equal(t, pb.StatusType_TRACK_STARTED, status.Type)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	assert.Eventually(t, func() bool {
+		tracks := rec.Tracks()
+		return len(tracks) == 1 && tracks[0].VolumeDb == -6
+	}, time.Second, 10*time.Millisecond)
+	assert.Empty(t, statuses)
+}
+
+// Other events don't wait for the asset of a PLAY event to open
+func TestRecorder_PlayOpensWithoutLock(t *testing.T) {
+	src := &slowSrc{StreamingSrc: &toneSrc{}, delay: 500 * time.Millisecond}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	src.position = rec.clock.position
+	played := make(chan error)
+	go func() {
+		played <- rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone"})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	started := time.Now()
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_MASTER_VOLUME, VolumeDeltaDb: -3}))
+	assert.Empty(t, rec.Tracks())
+	assert.Less(t, time.Since(started), 200*time.Millisecond)
+	assert.NoError(t, <-played)
+	assert.Len(t, rec.Tracks(), 1)
+}
+
+func TestRecorder_Journal(t *testing.T) {
+	buf := &bytes.Buffer{}
+	journal, err := NewJournal(buf, DefaultFormat.SampleRate)
+	assert.NoError(t, err)
+	rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{Journal: journal})
+	firstSound, errCh := firstSoundSink(t, rec)
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 50}})
+	<-firstSound
+	assert.NoError(t, <-errCh)
+	rec.Update(&pb.Event{Type: pb.EventType_VOLUME, AssetUrl: "tone", VolumeDeltaDb: -6})
+	rec.Stop()
+
+	timeline, err := ReadJournal(buf)
+	assert.NoError(t, err)
+	assert.True(t, timeline.Complete)
+	assert.Len(t, timeline.Entries, 2)
+	// Scheduled events are recorded at their exact position
+	assert.Equal(t, DefaultFormat.SampleRate.N(50*time.Millisecond), timeline.Entries[0].Pos)
+	assert.Equal(t, pb.EventType_VOLUME, timeline.Entries[1].Event.Type)
+	assert.GreaterOrEqual(t, timeline.End, timeline.Entries[1].Pos)
+}
+
+func TestRecorder_LoopCount(t *testing.T) {
+	statuses := make(chan *pb.Status, 100)
+	rec := NewRecorder(&shortToneSrc{length: 4800}, DefaultFormat, RecorderOpt{OnStatus: func(status *pb.Status) { statuses <- status }})
+	defer rec.Stop()
+	_, err := rec.AddSink("drain", nil, drainEncode)
+	assert.NoError(t, err)
+
+	err = rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "short", Loop: true, LoopStartMs: 50, LoopEndMs: 20})
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+	<-statuses
+	// The track starts over twice, then plays on until its end
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "short", Loop: true, LoopCount: 2, LoopStartMs: 50}))
+	var types []pb.StatusType
+	for len(types) == 0 || types[len(types)-1] != pb.StatusType_TRACK_ENDED {
+		select {
+		case status := <-statuses:
+			types = append(types, status.Type)
+		case <-time.After(5 * time.Second):
+			assert.FailNow(t, "timeout")
+		}
+	}
+	assert.Equal(t, []pb.StatusType{pb.StatusType_TRACK_STARTED, pb.StatusType_TRACK_LOOPED, pb.StatusType_TRACK_LOOPED, pb.StatusType_TRACK_ENDED}, types)
+}
+
+func TestRecorder_Status(t *testing.T) {
+	statuses := make(chan *pb.Status, 100)
+	next := func() *pb.Status {
+		select {
+		case status := <-statuses:
+			return status
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "timeout")
+			return &pb.Status{}
+		}
+	}
+	rec := NewRecorder(&shortToneSrc{length: 4800}, DefaultFormat, RecorderOpt{OnStatus: func(status *pb.Status) { statuses <- status }})
+	defer rec.Stop()
+	_, err := rec.AddSink("drain", nil, drainEncode)
+	assert.NoError(t, err)
+
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "short", EvtId: "1", Loop: true})
+	status := next()
+	assert.Equal(t, pb.StatusType_TRACK_STARTED, status.Type)
+	assert.Equal(t, "1", status.EvtId)
+	assert.Equal(t, pb.StatusType_TRACK_LOOPED, next().Type)
+	rec.Update(&pb.Event{Type: pb.EventType_OTHER, AssetUrl: "short", Loop: false})
+	// The loop may have been started over meanwhile
+	for status = next(); status.Type == pb.StatusType_TRACK_LOOPED; status = next() {
+	}
+	assert.Equal(t, pb.StatusType_TRACK_ENDED, status.Type)
+	assert.Equal(t, "short", status.TrackId)
+	assert.Greater(t, status.PositionMs, int64(0))
+
+	rec.Update(&pb.Event{Type: pb.EventType_PAUSE, AssetUrl: "unknown", EvtId: "2"})
+	status = next()
+	assert.Equal(t, pb.StatusType_EVENT_ERROR, status.Type)
+	assert.Equal(t, "2", status.EvtId)
+	assert.NotEmpty(t, status.Error)
+	rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "missing"})
+	assert.Equal(t, pb.StatusType_LOAD_ERROR, next().Type)
+}
+
+func TestRecorder_Preload(t *testing.T) {
+	statuses := make(chan *pb.Status, 100)
+	next := func() *pb.Status {
+		select {
+		case status := <-statuses:
+			return status
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, "timeout")
+			return &pb.Status{}
+		}
+	}
+	src := &sineSrc{amplitude: 0.1, length: DefaultFormat.SampleRate.N(5 * time.Second)}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{OnStatus: func(status *pb.Status) { statuses <- status }})
+	defer rec.Stop()
+
+	// The asset is measured and opened ahead of time, the PLAY event doesn't open it again
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD, AssetUrl: "sine", EvtId: "1", NormalizeLufs: proto.Float64(-14)}))
+	status := next()
+	assert.Equal(t, pb.StatusType_PRELOAD_FINISHED, status.Type)
+	assert.Equal(t, "1", status.EvtId)
+	assert.Equal(t, 2, src.opened())
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "a", NormalizeLufs: proto.Float64(-14)}))
+	assert.Equal(t, pb.StatusType_TRACK_STARTED, next().Type)
+	assert.Equal(t, 2, src.opened())
+	assert.InDelta(t, 6, rec.Tracks()[0].VolumeDb, 0.1)
+	// A PLAY event failing doesn't use up a preloaded stream
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD, AssetUrl: "sine", EvtId: "3"}))
+	assert.Equal(t, pb.StatusType_PRELOAD_FINISHED, next().Type)
+	assert.Equal(t, 3, src.opened())
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "a"}), ErrTrackExists)
+	assert.Equal(t, pb.StatusType_LOAD_ERROR, next().Type)
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "b"}))
+	assert.Equal(t, pb.StatusType_TRACK_STARTED, next().Type)
+	assert.Equal(t, 3, src.opened())
+	// A preloaded stream is only played once
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "c"}))
+	assert.Equal(t, pb.StatusType_TRACK_STARTED, next().Type)
+	assert.Equal(t, 4, src.opened())
+
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD, AssetUrl: "missing", EvtId: "2"}))
+	status = next()
+	assert.Equal(t, pb.StatusType_PRELOAD_ERROR, status.Type)
+	assert.Equal(t, "2", status.EvtId)
+	assert.NotEmpty(t, status.Error)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD}), ErrInvalidEvent)
+}
+
+func TestRecorder_PreloadLimits(t *testing.T) {
+	statuses := make(chan *pb.Status, 100)
+	src := &sineSrc{amplitude: 0.1, length: DefaultFormat.SampleRate.N(time.Second)}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{PreloadTTL: 100 * time.Millisecond, OnStatus: func(status *pb.Status) { statuses <- status }})
+	defer rec.Stop()
+	preloaded := func() int {
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		return len(rec.preloaded["sine"])
+	}
+
+	for i := 0; i < maxPreloadsPerAsset; i++ {
+		assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD, AssetUrl: "sine"}))
+	}
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD, AssetUrl: "sine"}), ErrTooManyPreloads)
+	for i := 0; i <= maxPreloadsPerAsset; i++ {
+		status := <-statuses
+		if status.Type == pb.StatusType_PRELOAD_ERROR {
+			assert.NotEmpty(t, status.Error)
+		} else {
+			assert.Equal(t, pb.StatusType_PRELOAD_FINISHED, status.Type)
+		}
+	}
+	assert.Equal(t, maxPreloadsPerAsset, preloaded())
+	// Preloaded streams never played are released
+	assert.Eventually(t, func() bool { return preloaded() == 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PRELOAD, AssetUrl: "sine"}))
+}
+
+func TestRecorder_Seek(t *testing.T) {
+	for _, live := range []bool{false, true} {
+		src := &sineSrc{amplitude: 0.5, length: 10 * 48000, live: live}
+		rec := NewRecorder(src, DefaultFormat, RecorderOpt{})
+		assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", VolumeDeltaDb: -3}))
+		assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_VOLUME, AssetUrl: "sine", VolumeDeltaDb: -3}))
+		assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PAUSE, AssetUrl: "sine"}))
+		assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_SEEK, AssetUrl: "sine", SeekPositionMs: proto.Int64(1234)}))
+		assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_SEEK, AssetUrl: "sine", SeekPositionMs: proto.Int64(-1)}), ErrInvalidEvent)
+
+		// The track keeps its volume and stays paused
+		tracks := rec.Tracks()
+		assert.Len(t, tracks, 1)
+		assert.InDelta(t, -6, tracks[0].VolumeDb, 1e-9)
+		assert.True(t, tracks[0].Paused)
+		// Live streams are opened again from the position, and count their own position from 0
+		assert.Equal(t, 1234*time.Millisecond, tracks[0].Position)
+		if live {
+			assert.Equal(t, 2, src.opened())
+		} else {
+			assert.Equal(t, 1, src.opened())
+		}
+		rec.Stop()
+	}
+}
+
+func TestRecorder_UpdateErrors(t *testing.T) {
+	rec := NewRecorder(&shortToneSrc{length: 48000}, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "a"}))
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "a"}), ErrTrackExists)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "missing"}), ErrLoad)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_VOLUME, AssetUrl: "b", VolumeDeltaDb: -3}), ErrTrackNotFound)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_SEEK, AssetUrl: "b"}), ErrTrackNotFound)
+	assert.ErrorIs(t, rec.Update(&pb.Event{AssetUrl: "a"}), ErrInvalidEvent)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: 42, AssetUrl: "a"}), ErrInvalidEvent)
+	// Scheduled events are only checked once applied
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_STOP, AssetUrl: "b", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 0}}))
+}
+
+func TestRecorder_Tracks(t *testing.T) {
+	started := time.Now()
+	rec := NewRecorder(&shortToneSrc{length: 96000}, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	assert.WithinDuration(t, started, rec.Started(), 100*time.Millisecond)
+	assert.Empty(t, rec.Tracks())
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "short", TrackId: "b", Loop: true}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "short", TrackId: "a", VolumeDeltaDb: -3}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PAUSE, TrackId: "b"}))
+	time.Sleep(100 * time.Millisecond)
+
+	assert.GreaterOrEqual(t, rec.Elapsed(), 100*time.Millisecond)
+	tracks := rec.Tracks()
+	assert.Len(t, tracks, 2)
+	assert.Equal(t, "a", tracks[0].Id)
+	assert.Equal(t, "short", tracks[0].AssetUrl)
+	assert.Equal(t, -3.0, tracks[0].VolumeDb)
+	assert.False(t, tracks[0].Paused)
+	assert.True(t, tracks[1].Paused)
+	assert.True(t, tracks[1].Loop)
+}
+
+func TestRecorder_Pause(t *testing.T) {
+	rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	assert.ErrorIs(t, rec.Resume(), ErrNotPaused)
+	assert.NoError(t, rec.Pause())
+	assert.True(t, rec.Paused())
+	assert.ErrorIs(t, rec.Pause(), ErrPaused)
+	// Events are still applied while paused
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone"}))
+	assert.NoError(t, rec.Resume())
+	assert.False(t, rec.Paused())
+	firstSound, errCh := firstSoundSink(t, rec)
+	select {
+	case <-firstSound:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "timeout")
+	}
+	assert.NoError(t, <-errCh)
+}
+
+func TestRecorder_Master(t *testing.T) {
+	timeline := &Timeline{
+		SampleRate: DefaultFormat.SampleRate,
+		Entries: []JournalEntry{
+			// Two loud tracks overlapping would clip without the limiter
+			{Pos: 0, Event: &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", TrackId: "a"}},
+			{Pos: 0, Event: &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", TrackId: "b"}},
+			{Pos: 12000, Event: &pb.Event{Type: pb.EventType_MASTER_VOLUME, VolumeTargetDb: proto.Float64(-12)}},
+			{Pos: 24000, Event: &pb.Event{Type: pb.EventType_MASTER_MUTE}},
+		},
+		End: 36000,
+	}
+	var mix [][2]float64
+	err := Render(&toneSrc{}, DefaultFormat, RecorderOpt{}, timeline, nil, func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		return drainInto(&mix, s)
+	})
+	assert.NoError(t, err)
+	ceiling := math.Pow(10, master_bus.DefaultLimiter.CeilingDb/20)
+	for _, sample := range mix[:12000] {
+		assert.LessOrEqual(t, sample[0], ceiling)
+	}
+	assert.InDelta(t, ceiling, mix[11999][0], 1e-6)
+	// Events on the master bus are heard at their position, despite the look-ahead of the limiter.
+	// The limiter then slowly releases its gain reduction
+	assert.InDelta(t, ceiling*math.Pow(10, -12.0/20), mix[12000][0], 1e-3)
+	assert.Equal(t, 0.0, mix[len(mix)-1][0])
+
+	rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_MASTER_VOLUME, VolumeDeltaDb: -3}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_MASTER_VOLUME, VolumeDeltaDb: -3}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_MASTER_MUTE}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_LIMITER, Limiter: &pb.LimiterSettings{Enabled: true, CeilingDb: -3}}))
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_LIMITER}), ErrInvalidEvent)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_LIMITER, Limiter: &pb.LimiterSettings{CeilingDb: 3}}), ErrInvalidEvent)
+	master := rec.Master()
+	assert.Equal(t, -6.0, master.GainDb)
+	assert.True(t, master.Muted)
+	assert.Equal(t, master_bus.LimiterOpt{Enabled: true, CeilingDb: -3, Release: master_bus.DefaultLimiter.Release}, master.Limiter)
+}
+
+func TestRecorder_Ducking(t *testing.T) {
+	ducking := &pb.DuckingSettings{Enabled: true, ThresholdDb: -40, AttenuationDb: -12, AttackMs: 10, ReleaseMs: 100}
+	timeline := &Timeline{
+		SampleRate: DefaultFormat.SampleRate,
+		Entries: []JournalEntry{
+			{Pos: 0, Event: &pb.Event{Type: pb.EventType_DUCKING, Ducking: ducking}},
+			{Pos: 0, Event: &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", TrackId: "music", Role: pb.TrackRole_ROLE_MUSIC, VolumeDeltaDb: -6}},
+			{Pos: 12000, Event: &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", TrackId: "effect", Role: pb.TrackRole_ROLE_EFFECT, VolumeDeltaDb: -20}},
+			{Pos: 24000, Event: &pb.Event{Type: pb.EventType_STOP, TrackId: "effect"}},
+		},
+		End: 60000,
+	}
+	var mix [][2]float64
+	err := Render(&toneSrc{}, DefaultFormat, RecorderOpt{}, timeline, nil, func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		return drainInto(&mix, s)
+	})
+	assert.NoError(t, err)
+	music, effect := 0.5*math.Pow(10, -6.0/20), 0.5*math.Pow(10, -20.0/20)
+	assert.InDelta(t, music, mix[11999][0], 1e-6)
+	assert.InDelta(t, music*math.Pow(10, -12.0/20)+effect, mix[23999][0], 1e-3)
+	assert.InDelta(t, music, mix[len(mix)-1][0], 1e-3)
+
+	rec := NewRecorder(&toneSrc{}, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	assert.Equal(t, disc_jockey.DefaultDucking, rec.Master().Ducking)
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", Role: pb.TrackRole_ROLE_VOICE}))
+	assert.Equal(t, disc_jockey.RoleVoice, rec.Tracks()[0].Role)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone", TrackId: "unknown", Role: 42}), ErrInvalidEvent)
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_DUCKING, Ducking: &pb.DuckingSettings{ThresholdDb: -30, AttenuationDb: -6}}))
+	assert.Equal(t, disc_jockey.DuckingOpt{ThresholdDb: -30, AttenuationDb: -6, Attack: disc_jockey.DefaultDucking.Attack, Release: disc_jockey.DefaultDucking.Release}, rec.Master().Ducking)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_DUCKING}), ErrInvalidEvent)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_DUCKING, Ducking: &pb.DuckingSettings{AttenuationDb: 6}}), ErrInvalidEvent)
+}
+
+func TestRecorder_Normalize(t *testing.T) {
+	src := &sineSrc{amplitude: 0.1, length: DefaultFormat.SampleRate.N(5 * time.Second)}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	// A 1kHz sine at -20dBFS on both channels is at -20 LUFS. The tracks start before the asset is measured,
+	// and are then brought to their target loudness
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "a", NormalizeLufs: proto.Float64(-14)}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "b", NormalizeLufs: proto.Float64(-23), VolumeDeltaDb: -3}))
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "c"}))
+	assert.Len(t, rec.Tracks(), 3)
+	assert.Eventually(t, func() bool {
+		tracks := rec.Tracks()
+		return math.Abs(tracks[0].VolumeDb-6) < 0.1 && math.Abs(tracks[1].VolumeDb+6) < 0.1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 0.0, rec.Tracks()[2].VolumeDb)
+	// The asset is measured once, and then opened for each track
+	assert.Equal(t, 4, src.opened())
+	// Once measured, the asset is normalized right away
+	assert.NoError(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "d", NormalizeLufs: proto.Float64(-14)}))
+	assert.InDelta(t, 6, rec.Tracks()[3].VolumeDb, 0.1)
+
+	// Only the beginning of endless streams is measured
+	endless := &sineSrc{amplitude: 0.1, length: math.MaxInt}
+	live := NewRecorder(endless, DefaultFormat, RecorderOpt{})
+	defer live.Stop()
+	assert.NoError(t, live.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "a", NormalizeLufs: proto.Float64(-14)}))
+	assert.Eventually(t, func() bool { return math.Abs(live.Tracks()[0].VolumeDb-6) < 0.1 }, time.Minute, 10*time.Millisecond)
+
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", TrackId: "e", NormalizeLufs: proto.Float64(3)}), ErrInvalidEvent)
+	assert.ErrorIs(t, rec.Update(&pb.Event{Type: pb.EventType_PLAY, AssetUrl: "missing", NormalizeLufs: proto.Float64(-14)}), ErrLoad)
+}
+
+// versionedSrc tells the version of its assets, the same for all of them
+type versionedSrc struct {
+	*sineSrc
+	version string
+}
+
+func (vs *versionedSrc) Version(string) (string, error) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+	return vs.version, nil
+}
+
+func (vs *versionedSrc) KnownVersion(url string) (string, bool) {
+	version, _ := vs.Version(url)
+	return version, true
+}
+
+// The loudness of an asset is measured again once the asset is replaced at the same URL
+func TestRecorder_NormalizeVersions(t *testing.T) {
+	src := &versionedSrc{sineSrc: &sineSrc{amplitude: 0.1, length: DefaultFormat.SampleRate.N(time.Second)}, version: "v1"}
+	rec := NewRecorder(src, DefaultFormat, RecorderOpt{})
+	defer rec.Stop()
+	play := &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "sine", NormalizeLufs: proto.Float64(-14)}
+	gain, err := rec.normalization(play)
+	assert.NoError(t, err)
+	assert.InDelta(t, 6, gain, 0.1)
+	_, err = rec.normalization(play)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, src.opened())
+
+	src.mu.Lock()
+	src.version, src.amplitude = "v2", 0.05
+	src.mu.Unlock()
+	gain, err = rec.normalization(play)
+	assert.NoError(t, err)
+	assert.InDelta(t, 12, gain, 0.1)
+	assert.Equal(t, 2, src.opened())
+}
+
+func TestRender(t *testing.T) {
+	timeline := &Timeline{
+		SampleRate: DefaultFormat.SampleRate,
+		Entries: []JournalEntry{
+			{Pos: 12345, Event: &pb.Event{Type: pb.EventType_PLAY, AssetUrl: "tone"}},
+			{Pos: 30000, Event: &pb.Event{Type: pb.EventType_STOP, AssetUrl: "tone"}},
+		},
+		// Longer than the capacity of the mix buffer, the encoder sets the pace
+		End:      DefaultFormat.SampleRate.N(sinkMaxLag) * 2,
+		Complete: true,
+	}
+	var mix [][2]float64
+	err := Render(&toneSrc{}, DefaultFormat, RecorderOpt{}, timeline, nil, func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		return drainInto(&mix, s)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mix, timeline.End)
+	assert.Equal(t, 0.0, mix[12344][0])
+	assert.NotEqual(t, 0.0, mix[12345][0])
+	assert.Equal(t, 0.0, mix[30000][0])
+	assert.Equal(t, 0.0, mix[len(mix)-1][0])
+
+	// Positions follow the sample rate of the new mix
+	format := DefaultFormat
+	format.SampleRate = DefaultFormat.SampleRate / 2
+	mix = nil
+	timeline.End = 48000
+	err = Render(&toneSrc{}, format, RecorderOpt{}, timeline, nil, func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		return drainInto(&mix, s)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, mix, 24000)
+	assert.Equal(t, 0.0, mix[6171][0])
+	assert.NotEqual(t, 0.0, mix[6173][0])
+
+	// Events which can't be applied are reported, once the whole mix is rendered
+	mix = nil
+	timeline.Entries[0].Event.AssetUrl = "missing"
+	err = Render(&sineSrc{amplitude: 0.1, length: 48000}, DefaultFormat, RecorderOpt{}, timeline, nil, func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		return drainInto(&mix, s)
+	})
+	assert.ErrorIs(t, err, ErrLoad)
+	assert.ErrorIs(t, err, ErrTrackNotFound)
+	assert.Len(t, mix, 48000)
+}
+
+// Adds a sink to the recorder, reporting the position of the first non-silent sample in the mix
+func firstSoundSink(t *testing.T, rec *Recorder) (chan int, chan error) {
+	firstSound := make(chan int, 1)
+	errCh, err := rec.AddSink("first-sound", nil, func(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) error {
+		samples := make([][2]float64, 64)
+		// The sink may have been added after the first blocks of mix
+		pos := s.(*fanOutReader).pos
+		for {
+			n, ok := s.Stream(samples)
+			if !ok {
+				return nil
+			}
+			for i := range samples[:n] {
+				if samples[i][0] != 0 {
+					firstSound <- pos + i
+					return nil
+				}
+			}
+			pos += n
+		}
+	})
+	assert.NoError(t, err)
+	return firstSound, errCh
+}
+
 type mockEncoder struct {
 	mock.Mock
 }
@@ -51,9 +729,98 @@ func (m *mockEncoder) Encode(w io.WriteSeeker, s beep.Streamer, format beep.Form
 	return args.Error(0)
 }
 
+// Encoder reading the stream until it is stopped or drained, without writing anything
+func drainEncode(w io.WriteSeeker, s beep.Streamer, format beep.Format, signalCh chan os.Signal) (err error) {
+	samples := make([][2]float64, 512)
+	for {
+		select {
+		case <-signalCh:
+			return nil
+		default:
+			if _, ok := s.Stream(samples); !ok {
+				return nil
+			}
+		}
+	}
+}
+
+// Reads the whole stream into mix
+func drainInto(mix *[][2]float64, s beep.Streamer) error {
+	samples := make([][2]float64, 1000)
+	for {
+		n, ok := s.Stream(samples)
+		*mix = append(*mix, samples[:n]...)
+		if !ok {
+			return nil
+		}
+	}
+}
+
 type fileStreamer struct {
 }
 
 func (fs *fileStreamer) GetStream(string) (beep.StreamSeekCloser, beep.Format, error) {
 	return nil, beep.Format{}, nil
 }
+
+// Streaming source always returning an endless silence
+type silenceSrc struct {
+}
+
+func (ss *silenceSrc) GetStream(string, time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	return &test_utils.Constant{}, DefaultFormat, nil
+}
+
+// Streaming source always returning an endless constant tone
+type toneSrc struct {
+}
+
+func (ts *toneSrc) GetStream(string, time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	return test_utils.NewConstant(0.5), DefaultFormat, nil
+}
+
+// Streaming source returning a constant tone of the given length, or an error for the "missing" asset.
+// Short tones can't be sought, they are opened again to start over
+type shortToneSrc struct {
+	length int
+}
+
+func (ss *shortToneSrc) GetStream(url string, offset time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	if url == "missing" {
+		return nil, beep.Format{}, fmt.Errorf("asset %s not found", url)
+	}
+	return &test_utils.Constant{Sample: [2]float64{0.5, 0.5}, Length: ss.length, Live: true}, DefaultFormat, nil
+}
+
+// Streaming source returning a 1kHz sine of the given amplitude and length, or an error for the "missing" asset.
+// Streams of a live source can't be sought
+type sineSrc struct {
+	amplitude float64
+	length    int
+	live      bool
+	mu        sync.Mutex
+	streams   int
+}
+
+func (ss *sineSrc) GetStream(url string, offset time.Duration) (beep.StreamSeekCloser, beep.Format, error) {
+	if url == "missing" {
+		return nil, beep.Format{}, fmt.Errorf("asset %s not found", url)
+	}
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.streams++
+	return &test_utils.Sine{
+		Amplitude:  ss.amplitude,
+		Frequency:  1000,
+		SampleRate: DefaultFormat.SampleRate,
+		Length:     ss.length,
+		Live:       ss.live,
+	}, DefaultFormat, nil
+}
+
+// opened returns the number of streams opened so far
+func (ss *sineSrc) opened() int {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	return ss.streams
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.28.1
+// 	protoc-gen-go v1.31.0
 // 	protoc        v4.24.2
 // source: proto/events.proto
 
@@ -31,29 +31,52 @@ const (
 	EventType_SEEK        EventType = 5
 	EventType_VOLUME      EventType = 6
 	EventType_OTHER       EventType = 7
+	// Changes the gain of the whole mix, as VOLUME does for a track
+	EventType_MASTER_VOLUME EventType = 8
+	// Silences the whole mix
+	EventType_MASTER_MUTE   EventType = 9
+	EventType_MASTER_UNMUTE EventType = 10
+	// Changes the settings of the limiter of the mix
+	EventType_LIMITER EventType = 11
+	// Changes how music tracks are ducked by effect and voice tracks
+	EventType_DUCKING EventType = 12
+	// Fetches an asset ahead of time, so that the next PLAY of this asset starts right away
+	EventType_PRELOAD EventType = 13
 )
 
 // Enum value maps for EventType.
 var (
 	EventType_name = map[int32]string{
-		0: "UNSPECIFIED",
-		1: "PLAY",
-		2: "PAUSE",
-		3: "RESUME",
-		4: "STOP",
-		5: "SEEK",
-		6: "VOLUME",
-		7: "OTHER",
+		0:  "UNSPECIFIED",
+		1:  "PLAY",
+		2:  "PAUSE",
+		3:  "RESUME",
+		4:  "STOP",
+		5:  "SEEK",
+		6:  "VOLUME",
+		7:  "OTHER",
+		8:  "MASTER_VOLUME",
+		9:  "MASTER_MUTE",
+		10: "MASTER_UNMUTE",
+		11: "LIMITER",
+		12: "DUCKING",
+		13: "PRELOAD",
 	}
 	EventType_value = map[string]int32{
-		"UNSPECIFIED": 0,
-		"PLAY":        1,
-		"PAUSE":       2,
-		"RESUME":      3,
-		"STOP":        4,
-		"SEEK":        5,
-		"VOLUME":      6,
-		"OTHER":       7,
+		"UNSPECIFIED":   0,
+		"PLAY":          1,
+		"PAUSE":         2,
+		"RESUME":        3,
+		"STOP":          4,
+		"SEEK":          5,
+		"VOLUME":        6,
+		"OTHER":         7,
+		"MASTER_VOLUME": 8,
+		"MASTER_MUTE":   9,
+		"MASTER_UNMUTE": 10,
+		"LIMITER":       11,
+		"DUCKING":       12,
+		"PRELOAD":       13,
 	}
 )
 
@@ -84,41 +107,1613 @@ func (EventType) EnumDescriptor() ([]byte, []int) {
 	return file_proto_events_proto_rawDescGZIP(), []int{0}
 }
 
+// Role of a track in the mix. Music tracks are ducked while effect or voice tracks are heard
+type TrackRole int32
+
+const (
+	TrackRole_ROLE_NONE   TrackRole = 0
+	TrackRole_ROLE_MUSIC  TrackRole = 1
+	TrackRole_ROLE_EFFECT TrackRole = 2
+	TrackRole_ROLE_VOICE  TrackRole = 3
+)
+
+// Enum value maps for TrackRole.
+var (
+	TrackRole_name = map[int32]string{
+		0: "ROLE_NONE",
+		1: "ROLE_MUSIC",
+		2: "ROLE_EFFECT",
+		3: "ROLE_VOICE",
+	}
+	TrackRole_value = map[string]int32{
+		"ROLE_NONE":   0,
+		"ROLE_MUSIC":  1,
+		"ROLE_EFFECT": 2,
+		"ROLE_VOICE":  3,
+	}
+)
+
+func (x TrackRole) Enum() *TrackRole {
+	p := new(TrackRole)
+	*p = x
+	return p
+}
+
+func (x TrackRole) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TrackRole) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_events_proto_enumTypes[1].Descriptor()
+}
+
+func (TrackRole) Type() protoreflect.EnumType {
+	return &file_proto_events_proto_enumTypes[1]
+}
+
+func (x TrackRole) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TrackRole.Descriptor instead.
+func (TrackRole) EnumDescriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{1}
+}
+
+// Reason why an event couldn't be applied
+type ErrorCode int32
+
+const (
+	ErrorCode_OK ErrorCode = 0
+	// The record targeted by the event is not running
+	ErrorCode_RECORD_NOT_FOUND ErrorCode = 1
+	// The track targeted by the event is not playing
+	ErrorCode_TRACK_NOT_FOUND ErrorCode = 2
+	// A track with the same id is already playing
+	ErrorCode_TRACK_EXISTS ErrorCode = 3
+	// The asset of the track couldn't be loaded
+	ErrorCode_LOAD_FAILED ErrorCode = 4
+	// The event can't be applied whatever the state of the mix
+	ErrorCode_INVALID_EVENT ErrorCode = 5
+	ErrorCode_INTERNAL      ErrorCode = 6
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0: "OK",
+		1: "RECORD_NOT_FOUND",
+		2: "TRACK_NOT_FOUND",
+		3: "TRACK_EXISTS",
+		4: "LOAD_FAILED",
+		5: "INVALID_EVENT",
+		6: "INTERNAL",
+	}
+	ErrorCode_value = map[string]int32{
+		"OK":               0,
+		"RECORD_NOT_FOUND": 1,
+		"TRACK_NOT_FOUND":  2,
+		"TRACK_EXISTS":     3,
+		"LOAD_FAILED":      4,
+		"INVALID_EVENT":    5,
+		"INTERNAL":         6,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_events_proto_enumTypes[2].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_proto_events_proto_enumTypes[2]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{2}
+}
+
+// Audio codec of a recording
+type Codec int32
+
+const (
+	Codec_OPUS Codec = 0
+	Codec_MP3  Codec = 1
+	Codec_AAC  Codec = 2
+	Codec_FLAC Codec = 3
+	Codec_WAV  Codec = 4
+)
+
+// Enum value maps for Codec.
+var (
+	Codec_name = map[int32]string{
+		0: "OPUS",
+		1: "MP3",
+		2: "AAC",
+		3: "FLAC",
+		4: "WAV",
+	}
+	Codec_value = map[string]int32{
+		"OPUS": 0,
+		"MP3":  1,
+		"AAC":  2,
+		"FLAC": 3,
+		"WAV":  4,
+	}
+)
+
+func (x Codec) Enum() *Codec {
+	p := new(Codec)
+	*p = x
+	return p
+}
+
+func (x Codec) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Codec) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_events_proto_enumTypes[3].Descriptor()
+}
+
+func (Codec) Type() protoreflect.EnumType {
+	return &file_proto_events_proto_enumTypes[3]
+}
+
+func (x Codec) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Codec.Descriptor instead.
+func (Codec) EnumDescriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{3}
+}
+
+// Kind of notification about a record
+type StatusType int32
+
+const (
+	// A track started playing after a PLAY event
+	StatusType_TRACK_STARTED StatusType = 0
+	// A track reached its end
+	StatusType_TRACK_ENDED StatusType = 1
+	// A track reached its end and started over
+	StatusType_TRACK_LOOPED StatusType = 2
+	// The asset of a track couldn't be loaded
+	StatusType_LOAD_ERROR StatusType = 3
+	// An event couldn't be applied
+	StatusType_EVENT_ERROR StatusType = 4
+	// The record is stopped and its file is complete
+	StatusType_RECORD_STOPPED StatusType = 5
+	// The record file has been uploaded to the object storage
+	StatusType_UPLOAD_FINISHED StatusType = 6
+	// The record file couldn't be uploaded to the object storage
+	StatusType_UPLOAD_ERROR StatusType = 7
+	// The whole record is paused
+	StatusType_RECORD_PAUSED StatusType = 8
+	// The whole record is resumed
+	StatusType_RECORD_RESUMED StatusType = 9
+	// An asset has been preloaded after a PRELOAD event, and is ready to be played
+	StatusType_PRELOAD_FINISHED StatusType = 10
+	// An asset couldn't be preloaded
+	StatusType_PRELOAD_ERROR StatusType = 11
+)
+
+// Enum value maps for StatusType.
+var (
+	StatusType_name = map[int32]string{
+		0:  "TRACK_STARTED",
+		1:  "TRACK_ENDED",
+		2:  "TRACK_LOOPED",
+		3:  "LOAD_ERROR",
+		4:  "EVENT_ERROR",
+		5:  "RECORD_STOPPED",
+		6:  "UPLOAD_FINISHED",
+		7:  "UPLOAD_ERROR",
+		8:  "RECORD_PAUSED",
+		9:  "RECORD_RESUMED",
+		10: "PRELOAD_FINISHED",
+		11: "PRELOAD_ERROR",
+	}
+	StatusType_value = map[string]int32{
+		"TRACK_STARTED":    0,
+		"TRACK_ENDED":      1,
+		"TRACK_LOOPED":     2,
+		"LOAD_ERROR":       3,
+		"EVENT_ERROR":      4,
+		"RECORD_STOPPED":   5,
+		"UPLOAD_FINISHED":  6,
+		"UPLOAD_ERROR":     7,
+		"RECORD_PAUSED":    8,
+		"RECORD_RESUMED":   9,
+		"PRELOAD_FINISHED": 10,
+		"PRELOAD_ERROR":    11,
+	}
+)
+
+func (x StatusType) Enum() *StatusType {
+	p := new(StatusType)
+	*p = x
+	return p
+}
+
+func (x StatusType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StatusType) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_events_proto_enumTypes[4].Descriptor()
+}
+
+func (StatusType) Type() protoreflect.EnumType {
+	return &file_proto_events_proto_enumTypes[4]
+}
+
+func (x StatusType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StatusType.Descriptor instead.
+func (StatusType) EnumDescriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{4}
+}
+
+// Settings of the peak limiter applied to the whole mix
+type LimiterSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Maximum level of the mix, in decibels relative to full scale. Must not be above 0
+	CeilingDb float64 `protobuf:"fixed64,2,opt,name=ceilingDb,proto3" json:"ceilingDb,omitempty"`
+	// Time it takes for the limiter to stop reducing the gain once a peak is over. Defaults to 100
+	ReleaseMs int64 `protobuf:"varint,3,opt,name=releaseMs,proto3" json:"releaseMs,omitempty"`
+}
+
+func (x *LimiterSettings) Reset() {
+	*x = LimiterSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LimiterSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LimiterSettings) ProtoMessage() {}
+
+func (x *LimiterSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LimiterSettings.ProtoReflect.Descriptor instead.
+func (*LimiterSettings) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *LimiterSettings) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *LimiterSettings) GetCeilingDb() float64 {
+	if x != nil {
+		return x.CeilingDb
+	}
+	return 0
+}
+
+func (x *LimiterSettings) GetReleaseMs() int64 {
+	if x != nil {
+		return x.ReleaseMs
+	}
+	return 0
+}
+
+// Settings of the ducking of music tracks by effect and voice tracks
+type DuckingSettings struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// Level of the effects and voices above which the music is ducked, in dBFS. Must not be above 0
+	ThresholdDb float64 `protobuf:"fixed64,2,opt,name=thresholdDb,proto3" json:"thresholdDb,omitempty"`
+	// Gain applied to the music while ducked, in decibels. Must not be above 0
+	AttenuationDb float64 `protobuf:"fixed64,3,opt,name=attenuationDb,proto3" json:"attenuationDb,omitempty"`
+	// Time it takes for the music to be ducked, and to come back. Default to 50 and 500
+	AttackMs  int64 `protobuf:"varint,4,opt,name=attackMs,proto3" json:"attackMs,omitempty"`
+	ReleaseMs int64 `protobuf:"varint,5,opt,name=releaseMs,proto3" json:"releaseMs,omitempty"`
+}
+
+func (x *DuckingSettings) Reset() {
+	*x = DuckingSettings{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DuckingSettings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DuckingSettings) ProtoMessage() {}
+
+func (x *DuckingSettings) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DuckingSettings.ProtoReflect.Descriptor instead.
+func (*DuckingSettings) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DuckingSettings) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *DuckingSettings) GetThresholdDb() float64 {
+	if x != nil {
+		return x.ThresholdDb
+	}
+	return 0
+}
+
+func (x *DuckingSettings) GetAttenuationDb() float64 {
+	if x != nil {
+		return x.AttenuationDb
+	}
+	return 0
+}
+
+func (x *DuckingSettings) GetAttackMs() int64 {
+	if x != nil {
+		return x.AttackMs
+	}
+	return 0
+}
+
+func (x *DuckingSettings) GetReleaseMs() int64 {
+	if x != nil {
+		return x.ReleaseMs
+	}
+	return 0
+}
+
 // Event message definition.
 type Event struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	RecordId string    `protobuf:"bytes,1,opt,name=recordId,proto3" json:"recordId,omitempty"`
-	EvtId    string    `protobuf:"bytes,2,opt,name=evtId,proto3" json:"evtId,omitempty"`
-	Type     EventType `protobuf:"varint,3,opt,name=type,proto3,enum=events.EventType" json:"type,omitempty"`
-	// Also asset ID
-	AssetUrl string `protobuf:"bytes,4,opt,name=assetUrl,proto3" json:"assetUrl,omitempty"`
-	Loop     bool   `protobuf:"varint,5,opt,name=loop,proto3" json:"loop,omitempty"`
-	// Volume change in decibels
-	VolumeDeltaDb float64 `protobuf:"fixed64,6,opt,name=volumeDeltaDb,proto3" json:"volumeDeltaDb,omitempty"`
-	// Seek position in seconds
-	SeekPositionSec int64 `protobuf:"varint,7,opt,name=seekPositionSec,proto3" json:"seekPositionSec,omitempty"`
+	RecordId string    `protobuf:"bytes,1,opt,name=recordId,proto3" json:"recordId,omitempty"`
+	EvtId    string    `protobuf:"bytes,2,opt,name=evtId,proto3" json:"evtId,omitempty"`
+	Type     EventType `protobuf:"varint,3,opt,name=type,proto3,enum=events.EventType" json:"type,omitempty"`
+	// Source of the track. Also used as the track ID if trackId is empty
+	AssetUrl string `protobuf:"bytes,4,opt,name=assetUrl,proto3" json:"assetUrl,omitempty"`
+	Loop     bool   `protobuf:"varint,5,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Volume change in decibels
+	VolumeDeltaDb float64 `protobuf:"fixed64,6,opt,name=volumeDeltaDb,proto3" json:"volumeDeltaDb,omitempty"`
+	// Seek position in seconds. See seekPositionMs for a millisecond precision
+	SeekPositionSec int64 `protobuf:"varint,7,opt,name=seekPositionSec,proto3" json:"seekPositionSec,omitempty"`
+	// Fade duration in milliseconds. On PLAY, the track ramps in from silence,
+	// on STOP, the track ramps out to silence before being removed,
+	// on VOLUME, the volume change is spread over this duration
+	FadeDurationMs int64 `protobuf:"varint,8,opt,name=fadeDurationMs,proto3" json:"fadeDurationMs,omitempty"`
+	// Absolute target volume in decibels for VOLUME events. When set, volumeDeltaDb is ignored
+	VolumeTargetDb *float64 `protobuf:"fixed64,9,opt,name=volumeTargetDb,proto3,oneof" json:"volumeTargetDb,omitempty"`
+	// ID of the track instance targeted by the event. The same asset can be played
+	// multiple times concurrently using different track IDs. Defaults to assetUrl
+	TrackId string `protobuf:"bytes,10,opt,name=trackId,proto3" json:"trackId,omitempty"`
+	// When set, the event is not applied when received but at the given time, to the sample.
+	// Events scheduled in the past are applied right away
+	//
+	// Types that are assignable to ApplyAt:
+	//	*Event_AtRecordMs
+	//	*Event_AtUnixMs
+	ApplyAt isEvent_ApplyAt `protobuf_oneof:"applyAt"`
+	// Settings of the limiter, for LIMITER events
+	Limiter *LimiterSettings `protobuf:"bytes,13,opt,name=limiter,proto3" json:"limiter,omitempty"`
+	// Target loudness in LUFS for PLAY events, or to measure the asset ahead of time for PRELOAD events. When set, the loudness of the asset is measured,
+	// and the track starts with the gain bringing it to this target. volumeDeltaDb is applied on top
+	NormalizeLufs *float64 `protobuf:"fixed64,14,opt,name=normalizeLufs,proto3,oneof" json:"normalizeLufs,omitempty"`
+	// Role of the track, for PLAY events
+	Role TrackRole `protobuf:"varint,15,opt,name=role,proto3,enum=events.TrackRole" json:"role,omitempty"`
+	// Settings of the ducking, for DUCKING events
+	Ducking *DuckingSettings `protobuf:"bytes,16,opt,name=ducking,proto3" json:"ducking,omitempty"`
+	// Number of times a looping track starts over before playing on until its end, 0 looping forever
+	LoopCount int32 `protobuf:"varint,17,opt,name=loopCount,proto3" json:"loopCount,omitempty"`
+	// Region of the asset played over when looping, in milliseconds. The first play starts at the start of the asset,
+	// the next ones at loopStartMs. A loopEndMs of 0 is the end of the asset
+	LoopStartMs int64 `protobuf:"varint,18,opt,name=loopStartMs,proto3" json:"loopStartMs,omitempty"`
+	LoopEndMs   int64 `protobuf:"varint,19,opt,name=loopEndMs,proto3" json:"loopEndMs,omitempty"`
+	// Seek position in milliseconds, overrides seekPositionSec when set
+	SeekPositionMs *int64 `protobuf:"varint,20,opt,name=seekPositionMs,proto3,oneof" json:"seekPositionMs,omitempty"`
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Event) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *Event) GetEvtId() string {
+	if x != nil {
+		return x.EvtId
+	}
+	return ""
+}
+
+func (x *Event) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_UNSPECIFIED
+}
+
+func (x *Event) GetAssetUrl() string {
+	if x != nil {
+		return x.AssetUrl
+	}
+	return ""
+}
+
+func (x *Event) GetLoop() bool {
+	if x != nil {
+		return x.Loop
+	}
+	return false
+}
+
+func (x *Event) GetVolumeDeltaDb() float64 {
+	if x != nil {
+		return x.VolumeDeltaDb
+	}
+	return 0
+}
+
+func (x *Event) GetSeekPositionSec() int64 {
+	if x != nil {
+		return x.SeekPositionSec
+	}
+	return 0
+}
+
+func (x *Event) GetFadeDurationMs() int64 {
+	if x != nil {
+		return x.FadeDurationMs
+	}
+	return 0
+}
+
+func (x *Event) GetVolumeTargetDb() float64 {
+	if x != nil && x.VolumeTargetDb != nil {
+		return *x.VolumeTargetDb
+	}
+	return 0
+}
+
+func (x *Event) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (m *Event) GetApplyAt() isEvent_ApplyAt {
+	if m != nil {
+		return m.ApplyAt
+	}
+	return nil
+}
+
+func (x *Event) GetAtRecordMs() int64 {
+	if x, ok := x.GetApplyAt().(*Event_AtRecordMs); ok {
+		return x.AtRecordMs
+	}
+	return 0
+}
+
+func (x *Event) GetAtUnixMs() int64 {
+	if x, ok := x.GetApplyAt().(*Event_AtUnixMs); ok {
+		return x.AtUnixMs
+	}
+	return 0
+}
+
+func (x *Event) GetLimiter() *LimiterSettings {
+	if x != nil {
+		return x.Limiter
+	}
+	return nil
+}
+
+func (x *Event) GetNormalizeLufs() float64 {
+	if x != nil && x.NormalizeLufs != nil {
+		return *x.NormalizeLufs
+	}
+	return 0
+}
+
+func (x *Event) GetRole() TrackRole {
+	if x != nil {
+		return x.Role
+	}
+	return TrackRole_ROLE_NONE
+}
+
+func (x *Event) GetDucking() *DuckingSettings {
+	if x != nil {
+		return x.Ducking
+	}
+	return nil
+}
+
+func (x *Event) GetLoopCount() int32 {
+	if x != nil {
+		return x.LoopCount
+	}
+	return 0
+}
+
+func (x *Event) GetLoopStartMs() int64 {
+	if x != nil {
+		return x.LoopStartMs
+	}
+	return 0
+}
+
+func (x *Event) GetLoopEndMs() int64 {
+	if x != nil {
+		return x.LoopEndMs
+	}
+	return 0
+}
+
+func (x *Event) GetSeekPositionMs() int64 {
+	if x != nil && x.SeekPositionMs != nil {
+		return *x.SeekPositionMs
+	}
+	return 0
+}
+
+type isEvent_ApplyAt interface {
+	isEvent_ApplyAt()
+}
+
+type Event_AtRecordMs struct {
+	// Position on the record timeline, in milliseconds since the start of the record
+	AtRecordMs int64 `protobuf:"varint,11,opt,name=atRecordMs,proto3,oneof"`
+}
+
+type Event_AtUnixMs struct {
+	// Wall-clock time, in milliseconds since the Unix epoch
+	AtUnixMs int64 `protobuf:"varint,12,opt,name=atUnixMs,proto3,oneof"`
+}
+
+func (*Event_AtRecordMs) isEvent_ApplyAt() {}
+
+func (*Event_AtUnixMs) isEvent_ApplyAt() {}
+
+type EventReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *EventReply) Reset() {
+	*x = EventReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventReply) ProtoMessage() {}
+
+func (x *EventReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventReply.ProtoReflect.Descriptor instead.
+func (*EventReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EventReply) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Result of an event sent through StreamEventsAck
+type EventAck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	EvtId string    `protobuf:"bytes,1,opt,name=evtId,proto3" json:"evtId,omitempty"`
+	Code  ErrorCode `protobuf:"varint,2,opt,name=code,proto3,enum=events.ErrorCode" json:"code,omitempty"`
+	Error string    `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// The event is scheduled, errors happening once it is applied are reported through Watch
+	Scheduled bool `protobuf:"varint,4,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+}
+
+func (x *EventAck) Reset() {
+	*x = EventAck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EventAck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventAck) ProtoMessage() {}
+
+func (x *EventAck) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventAck.ProtoReflect.Descriptor instead.
+func (*EventAck) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EventAck) GetEvtId() string {
+	if x != nil {
+		return x.EvtId
+	}
+	return ""
+}
+
+func (x *EventAck) GetCode() ErrorCode {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorCode_OK
+}
+
+func (x *EventAck) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *EventAck) GetScheduled() bool {
+	if x != nil {
+		return x.Scheduled
+	}
+	return false
+}
+
+// Encoding of a recording
+type OutputProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Codec Codec `protobuf:"varint,1,opt,name=codec,proto3,enum=events.Codec" json:"codec,omitempty"`
+	// Target bitrate in kbps. Ignored by lossless codecs, 0 uses the encoder default
+	BitrateKbps int32 `protobuf:"varint,2,opt,name=bitrateKbps,proto3" json:"bitrateKbps,omitempty"`
+	// Container format (ogg, webm, matroska, mp3, adts, flac, wav). Defaults to the usual container of the codec
+	Container string `protobuf:"bytes,3,opt,name=container,proto3" json:"container,omitempty"`
+	// Encode in-process instead of using ffmpeg. Only available for WAV, and for FLAC in a flac container
+	InProcess bool `protobuf:"varint,4,opt,name=inProcess,proto3" json:"inProcess,omitempty"`
+}
+
+func (x *OutputProfile) Reset() {
+	*x = OutputProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OutputProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutputProfile) ProtoMessage() {}
+
+func (x *OutputProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutputProfile.ProtoReflect.Descriptor instead.
+func (*OutputProfile) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *OutputProfile) GetCodec() Codec {
+	if x != nil {
+		return x.Codec
+	}
+	return Codec_OPUS
+}
+
+func (x *OutputProfile) GetBitrateKbps() int32 {
+	if x != nil {
+		return x.BitrateKbps
+	}
+	return 0
+}
+
+func (x *OutputProfile) GetContainer() string {
+	if x != nil {
+		return x.Container
+	}
+	return ""
+}
+
+func (x *OutputProfile) GetInProcess() bool {
+	if x != nil {
+		return x.InProcess
+	}
+	return false
+}
+
+// Format of the mix, shared by every stage from decoding the assets to encoding the recording
+type MixFormat struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Sample rate in Hz, defaults to 48000
+	SampleRate int32 `protobuf:"varint,1,opt,name=sampleRate,proto3" json:"sampleRate,omitempty"`
+	// Number of channels, 1 or 2. Defaults to 2
+	Channels int32 `protobuf:"varint,2,opt,name=channels,proto3" json:"channels,omitempty"`
+	// Bits per sample, 8, 16 or 24. Defaults to 16
+	BitDepth int32 `protobuf:"varint,3,opt,name=bitDepth,proto3" json:"bitDepth,omitempty"`
+	// Duration of the blocks the mix is rendered in, between 1 and 100 ms. Defaults to 10
+	BlockMs int32 `protobuf:"varint,4,opt,name=blockMs,proto3" json:"blockMs,omitempty"`
+}
+
+func (x *MixFormat) Reset() {
+	*x = MixFormat{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MixFormat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MixFormat) ProtoMessage() {}
+
+func (x *MixFormat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MixFormat.ProtoReflect.Descriptor instead.
+func (*MixFormat) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MixFormat) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *MixFormat) GetChannels() int32 {
+	if x != nil {
+		return x.Channels
+	}
+	return 0
+}
+
+func (x *MixFormat) GetBitDepth() int32 {
+	if x != nil {
+		return x.BitDepth
+	}
+	return 0
+}
+
+func (x *MixFormat) GetBlockMs() int32 {
+	if x != nil {
+		return x.BlockMs
+	}
+	return 0
+}
+
+type RecordRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Encoding of the recording, defaults to Opus in an Ogg container
+	Output *OutputProfile `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	// Format of the mix, defaults to 48kHz stereo 16 bits
+	Mix *MixFormat `protobuf:"bytes,3,opt,name=mix,proto3" json:"mix,omitempty"`
+}
+
+func (x *RecordRequest) Reset() {
+	*x = RecordRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordRequest) ProtoMessage() {}
+
+func (x *RecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordRequest.ProtoReflect.Descriptor instead.
+func (*RecordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RecordRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RecordRequest) GetOutput() *OutputProfile {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *RecordRequest) GetMix() *MixFormat {
+	if x != nil {
+		return x.Mix
+	}
+	return nil
+}
+
+type RecordReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *RecordReply) Reset() {
+	*x = RecordReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordReply) ProtoMessage() {}
+
+func (x *RecordReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordReply.ProtoReflect.Descriptor instead.
+func (*RecordReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RecordReply) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type StopRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *StopRequest) Reset() {
+	*x = StopRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRequest) ProtoMessage() {}
+
+func (x *StopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRequest.ProtoReflect.Descriptor instead.
+func (*StopRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StopRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type StopReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Message string `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+}
+
+func (x *StopReply) Reset() {
+	*x = StopReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StopReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopReply) ProtoMessage() {}
+
+func (x *StopReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopReply.ProtoReflect.Descriptor instead.
+func (*StopReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *StopReply) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Notification about a record
+type Status struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RecordId string     `protobuf:"bytes,1,opt,name=recordId,proto3" json:"recordId,omitempty"`
+	Type     StatusType `protobuf:"varint,2,opt,name=type,proto3,enum=events.StatusType" json:"type,omitempty"`
+	TrackId  string     `protobuf:"bytes,3,opt,name=trackId,proto3" json:"trackId,omitempty"`
+	AssetUrl string     `protobuf:"bytes,4,opt,name=assetUrl,proto3" json:"assetUrl,omitempty"`
+	// ID of the event the notification results from, if any
+	EvtId string `protobuf:"bytes,5,opt,name=evtId,proto3" json:"evtId,omitempty"`
+	// Reason of the failure, for errors
+	Error string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	// Position in the mix when the notification was emitted, in milliseconds
+	PositionMs int64 `protobuf:"varint,7,opt,name=positionMs,proto3" json:"positionMs,omitempty"`
+	// Key of the record file in the object storage, once uploaded
+	Key string `protobuf:"bytes,8,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Status) GetRecordId() string {
+	if x != nil {
+		return x.RecordId
+	}
+	return ""
+}
+
+func (x *Status) GetType() StatusType {
+	if x != nil {
+		return x.Type
+	}
+	return StatusType_TRACK_STARTED
+}
+
+func (x *Status) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *Status) GetAssetUrl() string {
+	if x != nil {
+		return x.AssetUrl
+	}
+	return ""
+}
+
+func (x *Status) GetEvtId() string {
+	if x != nil {
+		return x.EvtId
+	}
+	return ""
+}
+
+func (x *Status) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Status) GetPositionMs() int64 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+func (x *Status) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type WatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *WatchRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// State of a track of a record
+type TrackInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TrackId string `protobuf:"bytes,1,opt,name=trackId,proto3" json:"trackId,omitempty"`
+	// Empty for a track being faded out after a STOP event
+	AssetUrl string `protobuf:"bytes,2,opt,name=assetUrl,proto3" json:"assetUrl,omitempty"`
+	Paused   bool   `protobuf:"varint,3,opt,name=paused,proto3" json:"paused,omitempty"`
+	// Current volume in decibels
+	VolumeDb float64 `protobuf:"fixed64,4,opt,name=volumeDb,proto3" json:"volumeDb,omitempty"`
+	Loop     bool    `protobuf:"varint,5,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Playback position in the asset
+	PositionMs int64 `protobuf:"varint,6,opt,name=positionMs,proto3" json:"positionMs,omitempty"`
+	// Length of the asset and remaining duration to play, unset when unknown as for live streams
+	LengthMs    *int64    `protobuf:"varint,7,opt,name=lengthMs,proto3,oneof" json:"lengthMs,omitempty"`
+	RemainingMs *int64    `protobuf:"varint,8,opt,name=remainingMs,proto3,oneof" json:"remainingMs,omitempty"`
+	Role        TrackRole `protobuf:"varint,9,opt,name=role,proto3,enum=events.TrackRole" json:"role,omitempty"`
+	// Number of times the track started over so far
+	Loops int32 `protobuf:"varint,10,opt,name=loops,proto3" json:"loops,omitempty"`
+}
+
+func (x *TrackInfo) Reset() {
+	*x = TrackInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TrackInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackInfo) ProtoMessage() {}
+
+func (x *TrackInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackInfo.ProtoReflect.Descriptor instead.
+func (*TrackInfo) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TrackInfo) GetTrackId() string {
+	if x != nil {
+		return x.TrackId
+	}
+	return ""
+}
+
+func (x *TrackInfo) GetAssetUrl() string {
+	if x != nil {
+		return x.AssetUrl
+	}
+	return ""
+}
+
+func (x *TrackInfo) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *TrackInfo) GetVolumeDb() float64 {
+	if x != nil {
+		return x.VolumeDb
+	}
+	return 0
+}
+
+func (x *TrackInfo) GetLoop() bool {
+	if x != nil {
+		return x.Loop
+	}
+	return false
+}
+
+func (x *TrackInfo) GetPositionMs() int64 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+func (x *TrackInfo) GetLengthMs() int64 {
+	if x != nil && x.LengthMs != nil {
+		return *x.LengthMs
+	}
+	return 0
+}
+
+func (x *TrackInfo) GetRemainingMs() int64 {
+	if x != nil && x.RemainingMs != nil {
+		return *x.RemainingMs
+	}
+	return 0
+}
+
+func (x *TrackInfo) GetRole() TrackRole {
+	if x != nil {
+		return x.Role
+	}
+	return TrackRole_ROLE_NONE
+}
+
+func (x *TrackInfo) GetLoops() int32 {
+	if x != nil {
+		return x.Loops
+	}
+	return 0
+}
+
+// State of a running record
+type RecordInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Wall-clock time of the start of the record, in milliseconds since the Unix epoch
+	StartUnixMs int64 `protobuf:"varint,2,opt,name=startUnixMs,proto3" json:"startUnixMs,omitempty"`
+	// Duration of the mix so far
+	ElapsedMs int64        `protobuf:"varint,3,opt,name=elapsedMs,proto3" json:"elapsedMs,omitempty"`
+	Tracks    []*TrackInfo `protobuf:"bytes,4,rep,name=tracks,proto3" json:"tracks,omitempty"`
+	// The record is paused, the mix doesn't advance
+	Paused bool `protobuf:"varint,5,opt,name=paused,proto3" json:"paused,omitempty"`
+	// State of the master bus
+	MasterGainDb float64          `protobuf:"fixed64,6,opt,name=masterGainDb,proto3" json:"masterGainDb,omitempty"`
+	Muted        bool             `protobuf:"varint,7,opt,name=muted,proto3" json:"muted,omitempty"`
+	Limiter      *LimiterSettings `protobuf:"bytes,8,opt,name=limiter,proto3" json:"limiter,omitempty"`
+	Ducking      *DuckingSettings `protobuf:"bytes,9,opt,name=ducking,proto3" json:"ducking,omitempty"`
+	// Delay between the reception of the last event and the moment it was included in the mix
+	LatencyMs int64 `protobuf:"varint,10,opt,name=latencyMs,proto3" json:"latencyMs,omitempty"`
+}
+
+func (x *RecordInfo) Reset() {
+	*x = RecordInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RecordInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecordInfo) ProtoMessage() {}
+
+func (x *RecordInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecordInfo.ProtoReflect.Descriptor instead.
+func (*RecordInfo) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RecordInfo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RecordInfo) GetStartUnixMs() int64 {
+	if x != nil {
+		return x.StartUnixMs
+	}
+	return 0
+}
+
+func (x *RecordInfo) GetElapsedMs() int64 {
+	if x != nil {
+		return x.ElapsedMs
+	}
+	return 0
+}
+
+func (x *RecordInfo) GetTracks() []*TrackInfo {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *RecordInfo) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *RecordInfo) GetMasterGainDb() float64 {
+	if x != nil {
+		return x.MasterGainDb
+	}
+	return 0
+}
+
+func (x *RecordInfo) GetMuted() bool {
+	if x != nil {
+		return x.Muted
+	}
+	return false
+}
+
+func (x *RecordInfo) GetLimiter() *LimiterSettings {
+	if x != nil {
+		return x.Limiter
+	}
+	return nil
+}
+
+func (x *RecordInfo) GetDucking() *DuckingSettings {
+	if x != nil {
+		return x.Ducking
+	}
+	return nil
+}
+
+func (x *RecordInfo) GetLatencyMs() int64 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+type ListRecordsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListRecordsRequest) Reset() {
+	*x = ListRecordsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *ListRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecordsRequest) ProtoMessage() {}
+
+func (x *ListRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ListRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{15}
+}
+
+type ListRecordsReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Records []*RecordInfo `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+}
+
+func (x *ListRecordsReply) Reset() {
+	*x = ListRecordsReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_events_proto_msgTypes[0]
+		mi := &file_proto_events_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *Event) String() string {
+func (x *ListRecordsReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*ListRecordsReply) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_events_proto_msgTypes[0]
+func (x *ListRecordsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -129,61 +1724,244 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
-	return file_proto_events_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ListRecordsReply.ProtoReflect.Descriptor instead.
+func (*ListRecordsReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *Event) GetRecordId() string {
+func (x *ListRecordsReply) GetRecords() []*RecordInfo {
 	if x != nil {
-		return x.RecordId
+		return x.Records
 	}
-	return ""
+	return nil
 }
 
-func (x *Event) GetEvtId() string {
+type GetRecordRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetRecordRequest) Reset() {
+	*x = GetRecordRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRecordRequest) ProtoMessage() {}
+
+func (x *GetRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRecordRequest.ProtoReflect.Descriptor instead.
+func (*GetRecordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *GetRecordRequest) GetId() string {
 	if x != nil {
-		return x.EvtId
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Event) GetType() EventType {
-	if x != nil {
-		return x.Type
+type RenderRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Events of the whole mix, each positioned on the record timeline with atRecordMs
+	Events []*Event `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	// Duration of the mix. Defaults to the position of the last event
+	DurationMs int64 `protobuf:"varint,3,opt,name=durationMs,proto3" json:"durationMs,omitempty"`
+	// Encoding of the record file, defaults to Opus in an Ogg container
+	Output *OutputProfile `protobuf:"bytes,4,opt,name=output,proto3" json:"output,omitempty"`
+	// Format of the mix, defaults to 48kHz stereo 16 bits
+	Mix *MixFormat `protobuf:"bytes,5,opt,name=mix,proto3" json:"mix,omitempty"`
+}
+
+func (x *RenderRequest) Reset() {
+	*x = RenderRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	return EventType_UNSPECIFIED
 }
 
-func (x *Event) GetAssetUrl() string {
+func (x *RenderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderRequest) ProtoMessage() {}
+
+func (x *RenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderRequest.ProtoReflect.Descriptor instead.
+func (*RenderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *RenderRequest) GetId() string {
 	if x != nil {
-		return x.AssetUrl
+		return x.Id
 	}
 	return ""
 }
 
-func (x *Event) GetLoop() bool {
+func (x *RenderRequest) GetEvents() []*Event {
 	if x != nil {
-		return x.Loop
+		return x.Events
 	}
-	return false
+	return nil
 }
 
-func (x *Event) GetVolumeDeltaDb() float64 {
+func (x *RenderRequest) GetDurationMs() int64 {
 	if x != nil {
-		return x.VolumeDeltaDb
+		return x.DurationMs
 	}
 	return 0
 }
 
-func (x *Event) GetSeekPositionSec() int64 {
+func (x *RenderRequest) GetOutput() *OutputProfile {
 	if x != nil {
-		return x.SeekPositionSec
+		return x.Output
 	}
-	return 0
+	return nil
 }
 
-type EventReply struct {
+func (x *RenderRequest) GetMix() *MixFormat {
+	if x != nil {
+		return x.Mix
+	}
+	return nil
+}
+
+type RenderReply struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Location of the record file
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+}
+
+func (x *RenderReply) Reset() {
+	*x = RenderReply{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RenderReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderReply) ProtoMessage() {}
+
+func (x *RenderReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderReply.ProtoReflect.Descriptor instead.
+func (*RenderReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RenderReply) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type PauseRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *PauseRequest) Reset() {
+	*x = PauseRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_proto_events_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PauseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseRequest) ProtoMessage() {}
+
+func (x *PauseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseRequest.ProtoReflect.Descriptor instead.
+func (*PauseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PauseRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type PauseReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
@@ -191,23 +1969,23 @@ type EventReply struct {
 	Message string `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
 }
 
-func (x *EventReply) Reset() {
-	*x = EventReply{}
+func (x *PauseReply) Reset() {
+	*x = PauseReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_events_proto_msgTypes[1]
+		mi := &file_proto_events_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *EventReply) String() string {
+func (x *PauseReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*EventReply) ProtoMessage() {}
+func (*PauseReply) ProtoMessage() {}
 
-func (x *EventReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_events_proto_msgTypes[1]
+func (x *PauseReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -218,19 +1996,19 @@ func (x *EventReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use EventReply.ProtoReflect.Descriptor instead.
-func (*EventReply) Descriptor() ([]byte, []int) {
-	return file_proto_events_proto_rawDescGZIP(), []int{1}
+// Deprecated: Use PauseReply.ProtoReflect.Descriptor instead.
+func (*PauseReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *EventReply) GetMessage() string {
+func (x *PauseReply) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-type RecordRequest struct {
+type ResumeRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
@@ -238,23 +2016,23 @@ type RecordRequest struct {
 	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 }
 
-func (x *RecordRequest) Reset() {
-	*x = RecordRequest{}
+func (x *ResumeRequest) Reset() {
+	*x = ResumeRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_events_proto_msgTypes[2]
+		mi := &file_proto_events_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RecordRequest) String() string {
+func (x *ResumeRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordRequest) ProtoMessage() {}
+func (*ResumeRequest) ProtoMessage() {}
 
-func (x *RecordRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_events_proto_msgTypes[2]
+func (x *ResumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -265,43 +2043,43 @@ func (x *RecordRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordRequest.ProtoReflect.Descriptor instead.
-func (*RecordRequest) Descriptor() ([]byte, []int) {
-	return file_proto_events_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use ResumeRequest.ProtoReflect.Descriptor instead.
+func (*ResumeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{22}
 }
 
-func (x *RecordRequest) GetId() string {
+func (x *ResumeRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-type RecordReply struct {
+type ResumeReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	Message string `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
 }
-
-func (x *RecordReply) Reset() {
-	*x = RecordReply{}
+
+func (x *ResumeReply) Reset() {
+	*x = ResumeReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_events_proto_msgTypes[3]
+		mi := &file_proto_events_proto_msgTypes[23]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *RecordReply) String() string {
+func (x *ResumeReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RecordReply) ProtoMessage() {}
+func (*ResumeReply) ProtoMessage() {}
 
-func (x *RecordReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_events_proto_msgTypes[3]
+func (x *ResumeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[23]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -312,43 +2090,48 @@ func (x *RecordReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RecordReply.ProtoReflect.Descriptor instead.
-func (*RecordReply) Descriptor() ([]byte, []int) {
-	return file_proto_events_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use ResumeReply.ProtoReflect.Descriptor instead.
+func (*ResumeReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{23}
 }
 
-func (x *RecordReply) GetMessage() string {
+func (x *ResumeReply) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-type StopRequest struct {
+type RerenderRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// Id of a stopped record, whose journal is replayed
 	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Encoding of the new record file, defaults to Opus in an Ogg container
+	Output *OutputProfile `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	// Format of the new mix, defaults to 48kHz stereo 16 bits
+	Mix *MixFormat `protobuf:"bytes,3,opt,name=mix,proto3" json:"mix,omitempty"`
 }
 
-func (x *StopRequest) Reset() {
-	*x = StopRequest{}
+func (x *RerenderRequest) Reset() {
+	*x = RerenderRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_events_proto_msgTypes[4]
+		mi := &file_proto_events_proto_msgTypes[24]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopRequest) String() string {
+func (x *RerenderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopRequest) ProtoMessage() {}
+func (*RerenderRequest) ProtoMessage() {}
 
-func (x *StopRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_events_proto_msgTypes[4]
+func (x *RerenderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[24]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -359,43 +2142,58 @@ func (x *StopRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopRequest.ProtoReflect.Descriptor instead.
-func (*StopRequest) Descriptor() ([]byte, []int) {
-	return file_proto_events_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use RerenderRequest.ProtoReflect.Descriptor instead.
+func (*RerenderRequest) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{24}
 }
 
-func (x *StopRequest) GetId() string {
+func (x *RerenderRequest) GetId() string {
 	if x != nil {
 		return x.Id
 	}
 	return ""
 }
 
-type StopReply struct {
+func (x *RerenderRequest) GetOutput() *OutputProfile {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *RerenderRequest) GetMix() *MixFormat {
+	if x != nil {
+		return x.Mix
+	}
+	return nil
+}
+
+type RerenderReply struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Message string `protobuf:"bytes,1,opt,name=Message,proto3" json:"Message,omitempty"`
+	// Location of the new record file
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 }
 
-func (x *StopReply) Reset() {
-	*x = StopReply{}
+func (x *RerenderReply) Reset() {
+	*x = RerenderReply{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_proto_events_proto_msgTypes[5]
+		mi := &file_proto_events_proto_msgTypes[25]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *StopReply) String() string {
+func (x *RerenderReply) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopReply) ProtoMessage() {}
+func (*RerenderReply) ProtoMessage() {}
 
-func (x *StopReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_events_proto_msgTypes[5]
+func (x *RerenderReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_events_proto_msgTypes[25]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -406,14 +2204,14 @@ func (x *StopReply) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopReply.ProtoReflect.Descriptor instead.
-func (*StopReply) Descriptor() ([]byte, []int) {
-	return file_proto_events_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use RerenderReply.ProtoReflect.Descriptor instead.
+func (*RerenderReply) Descriptor() ([]byte, []int) {
+	return file_proto_events_proto_rawDescGZIP(), []int{25}
 }
 
-func (x *StopReply) GetMessage() string {
+func (x *RerenderReply) GetKey() string {
 	if x != nil {
-		return x.Message
+		return x.Key
 	}
 	return ""
 }
@@ -422,52 +2220,300 @@ var File_proto_events_proto protoreflect.FileDescriptor
 
 var file_proto_events_proto_rawDesc = []byte{
 	0x0a, 0x12, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0xe0, 0x01, 0x0a,
-	0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
-	0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64,
-	0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x74, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x05, 0x65, 0x76, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e,
-	0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12,
-	0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x55, 0x72, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x55, 0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6c,
-	0x6f, 0x6f, 0x70, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x6f, 0x6f, 0x70, 0x12,
-	0x24, 0x0a, 0x0d, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x44, 0x62,
-	0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x44, 0x65,
-	0x6c, 0x74, 0x61, 0x44, 0x62, 0x12, 0x28, 0x0a, 0x0f, 0x73, 0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73,
-	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f,
-	0x73, 0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x22,
-	0x26, 0x0a, 0x0a, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a,
+	0x72, 0x6f, 0x74, 0x6f, 0x12, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x67, 0x0a, 0x0f,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x65, 0x69,
+	0x6c, 0x69, 0x6e, 0x67, 0x44, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x63, 0x65,
+	0x69, 0x6c, 0x69, 0x6e, 0x67, 0x44, 0x62, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x4d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x72, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x4d, 0x73, 0x22, 0xad, 0x01, 0x0a, 0x0f, 0x44, 0x75, 0x63, 0x6b, 0x69, 0x6e,
+	0x67, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x65, 0x6e, 0x61,
+	0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x65, 0x6e, 0x61, 0x62,
+	0x6c, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x44, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68,
+	0x6f, 0x6c, 0x64, 0x44, 0x62, 0x12, 0x24, 0x0a, 0x0d, 0x61, 0x74, 0x74, 0x65, 0x6e, 0x75, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x62, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x61, 0x74,
+	0x74, 0x65, 0x6e, 0x75, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x62, 0x12, 0x1a, 0x0a, 0x08, 0x61,
+	0x74, 0x74, 0x61, 0x63, 0x6b, 0x4d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x61,
+	0x74, 0x74, 0x61, 0x63, 0x6b, 0x4d, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x6c, 0x65, 0x61,
+	0x73, 0x65, 0x4d, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x72, 0x65, 0x6c, 0x65,
+	0x61, 0x73, 0x65, 0x4d, 0x73, 0x22, 0x95, 0x06, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12,
+	0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65,
+	0x76, 0x74, 0x49, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x76, 0x74, 0x49,
+	0x64, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79,
+	0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73, 0x65,
+	0x74, 0x55, 0x72, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65,
+	0x74, 0x55, 0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x6f, 0x70, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x04, 0x6c, 0x6f, 0x6f, 0x70, 0x12, 0x24, 0x0a, 0x0d, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x44, 0x62, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0d, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x44, 0x65, 0x6c, 0x74, 0x61, 0x44, 0x62, 0x12, 0x28,
+	0x0a, 0x0f, 0x73, 0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65,
+	0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x73, 0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x63, 0x12, 0x26, 0x0a, 0x0e, 0x66, 0x61, 0x64, 0x65,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0e, 0x66, 0x61, 0x64, 0x65, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73,
+	0x12, 0x2b, 0x0a, 0x0e, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x44, 0x62, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x48, 0x01, 0x52, 0x0e, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x44, 0x62, 0x88, 0x01, 0x01, 0x12, 0x18, 0x0a,
+	0x07, 0x74, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x74, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x20, 0x0a, 0x0a, 0x61, 0x74, 0x52, 0x65, 0x63,
+	0x6f, 0x72, 0x64, 0x4d, 0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x0a, 0x61,
+	0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x4d, 0x73, 0x12, 0x1c, 0x0a, 0x08, 0x61, 0x74, 0x55,
+	0x6e, 0x69, 0x78, 0x4d, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x08, 0x61,
+	0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x12, 0x31, 0x0a, 0x07, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x65, 0x72, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x2e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67,
+	0x73, 0x52, 0x07, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x12, 0x29, 0x0a, 0x0d, 0x6e, 0x6f,
+	0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x4c, 0x75, 0x66, 0x73, 0x18, 0x0e, 0x20, 0x01, 0x28,
+	0x01, 0x48, 0x02, 0x52, 0x0d, 0x6e, 0x6f, 0x72, 0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x4c, 0x75,
+	0x66, 0x73, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x0f, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x54, 0x72, 0x61,
+	0x63, 0x6b, 0x52, 0x6f, 0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x31, 0x0a, 0x07,
+	0x64, 0x75, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x10, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x44, 0x75, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x53, 0x65,
+	0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x52, 0x07, 0x64, 0x75, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x12,
+	0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6f, 0x70, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x11, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x09, 0x6c, 0x6f, 0x6f, 0x70, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x20, 0x0a,
+	0x0b, 0x6c, 0x6f, 0x6f, 0x70, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4d, 0x73, 0x18, 0x12, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x6c, 0x6f, 0x6f, 0x70, 0x53, 0x74, 0x61, 0x72, 0x74, 0x4d, 0x73, 0x12,
+	0x1c, 0x0a, 0x09, 0x6c, 0x6f, 0x6f, 0x70, 0x45, 0x6e, 0x64, 0x4d, 0x73, 0x18, 0x13, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x6c, 0x6f, 0x6f, 0x70, 0x45, 0x6e, 0x64, 0x4d, 0x73, 0x12, 0x2b, 0x0a,
+	0x0e, 0x73, 0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x18,
+	0x14, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x0e, 0x73, 0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x88, 0x01, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x61, 0x70,
+	0x70, 0x6c, 0x79, 0x41, 0x74, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x44, 0x62, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x6e, 0x6f, 0x72,
+	0x6d, 0x61, 0x6c, 0x69, 0x7a, 0x65, 0x4c, 0x75, 0x66, 0x73, 0x42, 0x11, 0x0a, 0x0f, 0x5f, 0x73,
+	0x65, 0x65, 0x6b, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x22, 0x26, 0x0a,
+	0x0a, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x4d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x7b, 0x0a, 0x08, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x41, 0x63,
+	0x6b, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x76, 0x74, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x65, 0x76, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x14,
+	0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65,
+	0x72, 0x72, 0x6f, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x64, 0x22, 0x92, 0x01, 0x0a, 0x0d, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x50, 0x72, 0x6f,
+	0x66, 0x69, 0x6c, 0x65, 0x12, 0x23, 0x0a, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x43, 0x6f, 0x64,
+	0x65, 0x63, 0x52, 0x05, 0x63, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x20, 0x0a, 0x0b, 0x62, 0x69, 0x74,
+	0x72, 0x61, 0x74, 0x65, 0x4b, 0x62, 0x70, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b,
+	0x62, 0x69, 0x74, 0x72, 0x61, 0x74, 0x65, 0x4b, 0x62, 0x70, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x63,
+	0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x63, 0x6f, 0x6e, 0x74, 0x61, 0x69, 0x6e, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x69, 0x6e, 0x50,
+	0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x69, 0x6e,
+	0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x22, 0x7d, 0x0a, 0x09, 0x4d, 0x69, 0x78, 0x46, 0x6f,
+	0x72, 0x6d, 0x61, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52, 0x61,
+	0x74, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65,
+	0x52, 0x61, 0x74, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x63, 0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x62, 0x69, 0x74, 0x44, 0x65, 0x70, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x62, 0x69, 0x74, 0x44, 0x65, 0x70, 0x74, 0x68, 0x12, 0x18, 0x0a, 0x07,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x73, 0x22, 0x73, 0x0a, 0x0d, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2d, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06,
+	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x23, 0x0a, 0x03, 0x6d, 0x69, 0x78, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4d, 0x69, 0x78,
+	0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x03, 0x6d, 0x69, 0x78, 0x22, 0x27, 0x0a, 0x0b, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x22, 0x1d, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x02, 0x69, 0x64, 0x22, 0x25, 0x0a, 0x09, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xe0, 0x01, 0x0a, 0x06, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49,
+	0x64, 0x12, 0x26, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x74, 0x72, 0x61,
+	0x63, 0x6b, 0x49, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x72, 0x61, 0x63,
+	0x6b, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x55, 0x72, 0x6c, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x55, 0x72, 0x6c, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x76, 0x74, 0x49, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x76, 0x74, 0x49, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x70, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x1e, 0x0a,
+	0x0c, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a,
+	0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xcb, 0x02,
+	0x0a, 0x09, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x49, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x72,
+	0x61, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x55, 0x72,
+	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x55, 0x72,
+	0x6c, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x76, 0x6f, 0x6c,
+	0x75, 0x6d, 0x65, 0x44, 0x62, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x08, 0x76, 0x6f, 0x6c,
+	0x75, 0x6d, 0x65, 0x44, 0x62, 0x12, 0x12, 0x0a, 0x04, 0x6c, 0x6f, 0x6f, 0x70, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x04, 0x6c, 0x6f, 0x6f, 0x70, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x6f, 0x73,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x70,
+	0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x1f, 0x0a, 0x08, 0x6c, 0x65, 0x6e,
+	0x67, 0x74, 0x68, 0x4d, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x08, 0x6c,
+	0x65, 0x6e, 0x67, 0x74, 0x68, 0x4d, 0x73, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0b, 0x72, 0x65,
+	0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x48,
+	0x01, 0x52, 0x0b, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x73, 0x88, 0x01,
+	0x01, 0x12, 0x25, 0x0a, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x52, 0x6f,
+	0x6c, 0x65, 0x52, 0x04, 0x72, 0x6f, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x6f, 0x6f, 0x70,
+	0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c, 0x6f, 0x6f, 0x70, 0x73, 0x42, 0x0b,
+	0x0a, 0x09, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x4d, 0x73, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x4d, 0x73, 0x22, 0xdd, 0x02, 0x0a, 0x0a,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x74,
+	0x61, 0x72, 0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x55, 0x6e, 0x69, 0x78, 0x4d, 0x73, 0x12, 0x1c, 0x0a, 0x09,
+	0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x4d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x65, 0x6c, 0x61, 0x70, 0x73, 0x65, 0x64, 0x4d, 0x73, 0x12, 0x29, 0x0a, 0x06, 0x74, 0x72,
+	0x61, 0x63, 0x6b, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x2e, 0x54, 0x72, 0x61, 0x63, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x06, 0x74,
+	0x72, 0x61, 0x63, 0x6b, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x75, 0x73, 0x65, 0x64, 0x12, 0x22, 0x0a,
+	0x0c, 0x6d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x47, 0x61, 0x69, 0x6e, 0x44, 0x62, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0c, 0x6d, 0x61, 0x73, 0x74, 0x65, 0x72, 0x47, 0x61, 0x69, 0x6e, 0x44,
+	0x62, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x75, 0x74, 0x65, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x05, 0x6d, 0x75, 0x74, 0x65, 0x64, 0x12, 0x31, 0x0a, 0x07, 0x6c, 0x69, 0x6d, 0x69, 0x74,
+	0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
+	0x73, 0x2e, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67,
+	0x73, 0x52, 0x07, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x72, 0x12, 0x31, 0x0a, 0x07, 0x64, 0x75,
+	0x63, 0x6b, 0x69, 0x6e, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x44, 0x75, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x53, 0x65, 0x74, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x52, 0x07, 0x64, 0x75, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x12, 0x1c, 0x0a,
+	0x09, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73, 0x22, 0x14, 0x0a, 0x12, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0x40, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73,
+	0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2c, 0x0a, 0x07, 0x72, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x72, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x22, 0x22, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0xba, 0x01, 0x0a, 0x0d, 0x52, 0x65, 0x6e, 0x64,
+	0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x25, 0x0a, 0x06, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73,
+	0x12, 0x2d, 0x0a, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x15, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12,
+	0x23, 0x0a, 0x03, 0x6d, 0x69, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4d, 0x69, 0x78, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52,
+	0x03, 0x6d, 0x69, 0x78, 0x22, 0x1f, 0x0a, 0x0b, 0x52, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x22, 0x1e, 0x0a, 0x0c, 0x50, 0x61, 0x75, 0x73, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x26, 0x0a, 0x0a, 0x50, 0x61, 0x75, 0x73, 0x65, 0x52, 0x65,
+	0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x1f, 0x0a,
+	0x0d, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x27,
+	0x0a, 0x0b, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a,
 	0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x1f, 0x0a, 0x0d, 0x52, 0x65, 0x63, 0x6f, 0x72,
-	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x27, 0x0a, 0x0b, 0x52, 0x65, 0x63, 0x6f,
-	0x72, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x22, 0x1d, 0x0a, 0x0b, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64,
-	0x22, 0x25, 0x0a, 0x09, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x18, 0x0a,
-	0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x2a, 0x68, 0x0a, 0x09, 0x45, 0x76, 0x65, 0x6e, 0x74,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46,
-	0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x4c, 0x41, 0x59, 0x10, 0x01, 0x12,
-	0x09, 0x0a, 0x05, 0x50, 0x41, 0x55, 0x53, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45,
-	0x53, 0x55, 0x4d, 0x45, 0x10, 0x03, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f, 0x50, 0x10, 0x04,
-	0x12, 0x08, 0x0a, 0x04, 0x53, 0x45, 0x45, 0x4b, 0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x56, 0x4f,
-	0x4c, 0x55, 0x4d, 0x45, 0x10, 0x06, 0x12, 0x09, 0x0a, 0x05, 0x4f, 0x54, 0x48, 0x45, 0x52, 0x10,
-	0x07, 0x32, 0xa7, 0x01, 0x0a, 0x0b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x53, 0x74, 0x72, 0x65, 0x61,
-	0x6d, 0x12, 0x33, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74,
-	0x73, 0x12, 0x0d, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74,
-	0x1a, 0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52,
-	0x65, 0x70, 0x6c, 0x79, 0x28, 0x01, 0x12, 0x33, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12,
-	0x15, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e,
-	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x04, 0x53,
-	0x74, 0x6f, 0x70, 0x12, 0x13, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x53, 0x74, 0x6f,
-	0x70, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74,
-	0x73, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x42, 0x12, 0x5a, 0x10, 0x2e,
-	0x2f, 0x6a, 0x75, 0x6b, 0x65, 0x62, 0x6f, 0x78, 0x2d, 0x73, 0x79, 0x6e, 0x63, 0x65, 0x72, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x75, 0x0a, 0x0f, 0x52, 0x65, 0x72, 0x65, 0x6e,
+	0x64, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x2d, 0x0a, 0x06, 0x6f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x65, 0x76, 0x65,
+	0x6e, 0x74, 0x73, 0x2e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c,
+	0x65, 0x52, 0x06, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x23, 0x0a, 0x03, 0x6d, 0x69, 0x78,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e,
+	0x4d, 0x69, 0x78, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x52, 0x03, 0x6d, 0x69, 0x78, 0x22, 0x21,
+	0x0a, 0x0d, 0x52, 0x65, 0x72, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
+	0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65,
+	0x79, 0x2a, 0xc6, 0x01, 0x0a, 0x09, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00,
+	0x12, 0x08, 0x0a, 0x04, 0x50, 0x4c, 0x41, 0x59, 0x10, 0x01, 0x12, 0x09, 0x0a, 0x05, 0x50, 0x41,
+	0x55, 0x53, 0x45, 0x10, 0x02, 0x12, 0x0a, 0x0a, 0x06, 0x52, 0x45, 0x53, 0x55, 0x4d, 0x45, 0x10,
+	0x03, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f, 0x50, 0x10, 0x04, 0x12, 0x08, 0x0a, 0x04, 0x53,
+	0x45, 0x45, 0x4b, 0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x56, 0x4f, 0x4c, 0x55, 0x4d, 0x45, 0x10,
+	0x06, 0x12, 0x09, 0x0a, 0x05, 0x4f, 0x54, 0x48, 0x45, 0x52, 0x10, 0x07, 0x12, 0x11, 0x0a, 0x0d,
+	0x4d, 0x41, 0x53, 0x54, 0x45, 0x52, 0x5f, 0x56, 0x4f, 0x4c, 0x55, 0x4d, 0x45, 0x10, 0x08, 0x12,
+	0x0f, 0x0a, 0x0b, 0x4d, 0x41, 0x53, 0x54, 0x45, 0x52, 0x5f, 0x4d, 0x55, 0x54, 0x45, 0x10, 0x09,
+	0x12, 0x11, 0x0a, 0x0d, 0x4d, 0x41, 0x53, 0x54, 0x45, 0x52, 0x5f, 0x55, 0x4e, 0x4d, 0x55, 0x54,
+	0x45, 0x10, 0x0a, 0x12, 0x0b, 0x0a, 0x07, 0x4c, 0x49, 0x4d, 0x49, 0x54, 0x45, 0x52, 0x10, 0x0b,
+	0x12, 0x0b, 0x0a, 0x07, 0x44, 0x55, 0x43, 0x4b, 0x49, 0x4e, 0x47, 0x10, 0x0c, 0x12, 0x0b, 0x0a,
+	0x07, 0x50, 0x52, 0x45, 0x4c, 0x4f, 0x41, 0x44, 0x10, 0x0d, 0x2a, 0x4b, 0x0a, 0x09, 0x54, 0x72,
+	0x61, 0x63, 0x6b, 0x52, 0x6f, 0x6c, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x52, 0x4f, 0x4c, 0x45, 0x5f,
+	0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x0e, 0x0a, 0x0a, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x4d,
+	0x55, 0x53, 0x49, 0x43, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x52, 0x4f, 0x4c, 0x45, 0x5f, 0x45,
+	0x46, 0x46, 0x45, 0x43, 0x54, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x52, 0x4f, 0x4c, 0x45, 0x5f,
+	0x56, 0x4f, 0x49, 0x43, 0x45, 0x10, 0x03, 0x2a, 0x82, 0x01, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x06, 0x0a, 0x02, 0x4f, 0x4b, 0x10, 0x00, 0x12, 0x14, 0x0a,
+	0x10, 0x52, 0x45, 0x43, 0x4f, 0x52, 0x44, 0x5f, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e,
+	0x44, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x52, 0x41, 0x43, 0x4b, 0x5f, 0x4e, 0x4f, 0x54,
+	0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x52, 0x41, 0x43,
+	0x4b, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54, 0x53, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x4c, 0x4f,
+	0x41, 0x44, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x12, 0x11, 0x0a, 0x0d, 0x49,
+	0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x10, 0x05, 0x12, 0x0c,
+	0x0a, 0x08, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x06, 0x2a, 0x36, 0x0a, 0x05,
+	0x43, 0x6f, 0x64, 0x65, 0x63, 0x12, 0x08, 0x0a, 0x04, 0x4f, 0x50, 0x55, 0x53, 0x10, 0x00, 0x12,
+	0x07, 0x0a, 0x03, 0x4d, 0x50, 0x33, 0x10, 0x01, 0x12, 0x07, 0x0a, 0x03, 0x41, 0x41, 0x43, 0x10,
+	0x02, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x4c, 0x41, 0x43, 0x10, 0x03, 0x12, 0x07, 0x0a, 0x03, 0x57,
+	0x41, 0x56, 0x10, 0x04, 0x2a, 0xee, 0x01, 0x0a, 0x0a, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x11, 0x0a, 0x0d, 0x54, 0x52, 0x41, 0x43, 0x4b, 0x5f, 0x53, 0x54, 0x41,
+	0x52, 0x54, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x54, 0x52, 0x41, 0x43, 0x4b, 0x5f,
+	0x45, 0x4e, 0x44, 0x45, 0x44, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x52, 0x41, 0x43, 0x4b,
+	0x5f, 0x4c, 0x4f, 0x4f, 0x50, 0x45, 0x44, 0x10, 0x02, 0x12, 0x0e, 0x0a, 0x0a, 0x4c, 0x4f, 0x41,
+	0x44, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x03, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x56, 0x45,
+	0x4e, 0x54, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x45,
+	0x43, 0x4f, 0x52, 0x44, 0x5f, 0x53, 0x54, 0x4f, 0x50, 0x50, 0x45, 0x44, 0x10, 0x05, 0x12, 0x13,
+	0x0a, 0x0f, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x5f, 0x46, 0x49, 0x4e, 0x49, 0x53, 0x48, 0x45,
+	0x44, 0x10, 0x06, 0x12, 0x10, 0x0a, 0x0c, 0x55, 0x50, 0x4c, 0x4f, 0x41, 0x44, 0x5f, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x10, 0x07, 0x12, 0x11, 0x0a, 0x0d, 0x52, 0x45, 0x43, 0x4f, 0x52, 0x44, 0x5f,
+	0x50, 0x41, 0x55, 0x53, 0x45, 0x44, 0x10, 0x08, 0x12, 0x12, 0x0a, 0x0e, 0x52, 0x45, 0x43, 0x4f,
+	0x52, 0x44, 0x5f, 0x52, 0x45, 0x53, 0x55, 0x4d, 0x45, 0x44, 0x10, 0x09, 0x12, 0x14, 0x0a, 0x10,
+	0x50, 0x52, 0x45, 0x4c, 0x4f, 0x41, 0x44, 0x5f, 0x46, 0x49, 0x4e, 0x49, 0x53, 0x48, 0x45, 0x44,
+	0x10, 0x0a, 0x12, 0x11, 0x0a, 0x0d, 0x50, 0x52, 0x45, 0x4c, 0x4f, 0x41, 0x44, 0x5f, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x10, 0x0b, 0x32, 0xeb, 0x04, 0x0a, 0x0b, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x33, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x0d, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x1a, 0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x28, 0x01, 0x12, 0x36, 0x0a, 0x0f, 0x53, 0x74,
+	0x72, 0x65, 0x61, 0x6d, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x41, 0x63, 0x6b, 0x12, 0x0d, 0x2e,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x1a, 0x10, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x41, 0x63, 0x6b, 0x28, 0x01,
+	0x30, 0x01, 0x12, 0x33, 0x0a, 0x05, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x15, 0x2e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x13, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x2e, 0x0a, 0x04, 0x53, 0x74, 0x6f, 0x70, 0x12,
+	0x13, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x53, 0x74, 0x6f, 0x70, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x11, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x53, 0x74,
+	0x6f, 0x70, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x50, 0x61, 0x75, 0x73, 0x65,
+	0x12, 0x14, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x50, 0x61, 0x75, 0x73, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e,
+	0x50, 0x61, 0x75, 0x73, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x34, 0x0a, 0x06, 0x52, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x12, 0x15, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65,
+	0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x73, 0x75, 0x6d, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x3a, 0x0a, 0x08, 0x52, 0x65, 0x72, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x17, 0x2e, 0x65,
+	0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x72, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52,
+	0x65, 0x72, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x34, 0x0a, 0x06,
+	0x52, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x12, 0x15, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e,
+	0x52, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13, 0x2e,
+	0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x6e, 0x64, 0x65, 0x72, 0x52, 0x65, 0x70,
+	0x6c, 0x79, 0x12, 0x2f, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x14, 0x2e, 0x65, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x0e, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x30, 0x01, 0x12, 0x43, 0x0a, 0x0b, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72,
+	0x64, 0x73, 0x12, 0x1a, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18,
+	0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x63, 0x6f,
+	0x72, 0x64, 0x73, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12, 0x39, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x52,
+	0x65, 0x63, 0x6f, 0x72, 0x64, 0x12, 0x18, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x47,
+	0x65, 0x74, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x12, 0x2e, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x2e, 0x52, 0x65, 0x63, 0x6f, 0x72, 0x64, 0x49,
+	0x6e, 0x66, 0x6f, 0x42, 0x12, 0x5a, 0x10, 0x2e, 0x2f, 0x6a, 0x75, 0x6b, 0x65, 0x62, 0x6f, 0x78,
+	0x2d, 0x73, 0x79, 0x6e, 0x63, 0x65, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -482,30 +2528,88 @@ func file_proto_events_proto_rawDescGZIP() []byte {
 	return file_proto_events_proto_rawDescData
 }
 
-var file_proto_events_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_events_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_events_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_proto_events_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
 var file_proto_events_proto_goTypes = []interface{}{
-	(EventType)(0),        // 0: events.EventType
-	(*Event)(nil),         // 1: events.Event
-	(*EventReply)(nil),    // 2: events.EventReply
-	(*RecordRequest)(nil), // 3: events.RecordRequest
-	(*RecordReply)(nil),   // 4: events.RecordReply
-	(*StopRequest)(nil),   // 5: events.StopRequest
-	(*StopReply)(nil),     // 6: events.StopReply
+	(EventType)(0),             // 0: events.EventType
+	(TrackRole)(0),             // 1: events.TrackRole
+	(ErrorCode)(0),             // 2: events.ErrorCode
+	(Codec)(0),                 // 3: events.Codec
+	(StatusType)(0),            // 4: events.StatusType
+	(*LimiterSettings)(nil),    // 5: events.LimiterSettings
+	(*DuckingSettings)(nil),    // 6: events.DuckingSettings
+	(*Event)(nil),              // 7: events.Event
+	(*EventReply)(nil),         // 8: events.EventReply
+	(*EventAck)(nil),           // 9: events.EventAck
+	(*OutputProfile)(nil),      // 10: events.OutputProfile
+	(*MixFormat)(nil),          // 11: events.MixFormat
+	(*RecordRequest)(nil),      // 12: events.RecordRequest
+	(*RecordReply)(nil),        // 13: events.RecordReply
+	(*StopRequest)(nil),        // 14: events.StopRequest
+	(*StopReply)(nil),          // 15: events.StopReply
+	(*Status)(nil),             // 16: events.Status
+	(*WatchRequest)(nil),       // 17: events.WatchRequest
+	(*TrackInfo)(nil),          // 18: events.TrackInfo
+	(*RecordInfo)(nil),         // 19: events.RecordInfo
+	(*ListRecordsRequest)(nil), // 20: events.ListRecordsRequest
+	(*ListRecordsReply)(nil),   // 21: events.ListRecordsReply
+	(*GetRecordRequest)(nil),   // 22: events.GetRecordRequest
+	(*RenderRequest)(nil),      // 23: events.RenderRequest
+	(*RenderReply)(nil),        // 24: events.RenderReply
+	(*PauseRequest)(nil),       // 25: events.PauseRequest
+	(*PauseReply)(nil),         // 26: events.PauseReply
+	(*ResumeRequest)(nil),      // 27: events.ResumeRequest
+	(*ResumeReply)(nil),        // 28: events.ResumeReply
+	(*RerenderRequest)(nil),    // 29: events.RerenderRequest
+	(*RerenderReply)(nil),      // 30: events.RerenderReply
 }
 var file_proto_events_proto_depIdxs = []int32{
-	0, // 0: events.Event.type:type_name -> events.EventType
-	1, // 1: events.EventStream.StreamEvents:input_type -> events.Event
-	3, // 2: events.EventStream.Start:input_type -> events.RecordRequest
-	5, // 3: events.EventStream.Stop:input_type -> events.StopRequest
-	2, // 4: events.EventStream.StreamEvents:output_type -> events.EventReply
-	4, // 5: events.EventStream.Start:output_type -> events.RecordReply
-	6, // 6: events.EventStream.Stop:output_type -> events.StopReply
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	0,  // 0: events.Event.type:type_name -> events.EventType
+	5,  // 1: events.Event.limiter:type_name -> events.LimiterSettings
+	1,  // 2: events.Event.role:type_name -> events.TrackRole
+	6,  // 3: events.Event.ducking:type_name -> events.DuckingSettings
+	2,  // 4: events.EventAck.code:type_name -> events.ErrorCode
+	3,  // 5: events.OutputProfile.codec:type_name -> events.Codec
+	10, // 6: events.RecordRequest.output:type_name -> events.OutputProfile
+	11, // 7: events.RecordRequest.mix:type_name -> events.MixFormat
+	4,  // 8: events.Status.type:type_name -> events.StatusType
+	1,  // 9: events.TrackInfo.role:type_name -> events.TrackRole
+	18, // 10: events.RecordInfo.tracks:type_name -> events.TrackInfo
+	5,  // 11: events.RecordInfo.limiter:type_name -> events.LimiterSettings
+	6,  // 12: events.RecordInfo.ducking:type_name -> events.DuckingSettings
+	19, // 13: events.ListRecordsReply.records:type_name -> events.RecordInfo
+	7,  // 14: events.RenderRequest.events:type_name -> events.Event
+	10, // 15: events.RenderRequest.output:type_name -> events.OutputProfile
+	11, // 16: events.RenderRequest.mix:type_name -> events.MixFormat
+	10, // 17: events.RerenderRequest.output:type_name -> events.OutputProfile
+	11, // 18: events.RerenderRequest.mix:type_name -> events.MixFormat
+	7,  // 19: events.EventStream.StreamEvents:input_type -> events.Event
+	7,  // 20: events.EventStream.StreamEventsAck:input_type -> events.Event
+	12, // 21: events.EventStream.Start:input_type -> events.RecordRequest
+	14, // 22: events.EventStream.Stop:input_type -> events.StopRequest
+	25, // 23: events.EventStream.Pause:input_type -> events.PauseRequest
+	27, // 24: events.EventStream.Resume:input_type -> events.ResumeRequest
+	29, // 25: events.EventStream.Rerender:input_type -> events.RerenderRequest
+	23, // 26: events.EventStream.Render:input_type -> events.RenderRequest
+	17, // 27: events.EventStream.Watch:input_type -> events.WatchRequest
+	20, // 28: events.EventStream.ListRecords:input_type -> events.ListRecordsRequest
+	22, // 29: events.EventStream.GetRecord:input_type -> events.GetRecordRequest
+	8,  // 30: events.EventStream.StreamEvents:output_type -> events.EventReply
+	9,  // 31: events.EventStream.StreamEventsAck:output_type -> events.EventAck
+	13, // 32: events.EventStream.Start:output_type -> events.RecordReply
+	15, // 33: events.EventStream.Stop:output_type -> events.StopReply
+	26, // 34: events.EventStream.Pause:output_type -> events.PauseReply
+	28, // 35: events.EventStream.Resume:output_type -> events.ResumeReply
+	30, // 36: events.EventStream.Rerender:output_type -> events.RerenderReply
+	24, // 37: events.EventStream.Render:output_type -> events.RenderReply
+	16, // 38: events.EventStream.Watch:output_type -> events.Status
+	21, // 39: events.EventStream.ListRecords:output_type -> events.ListRecordsReply
+	19, // 40: events.EventStream.GetRecord:output_type -> events.RecordInfo
+	30, // [30:41] is the sub-list for method output_type
+	19, // [19:30] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_proto_events_proto_init() }
@@ -515,7 +2619,7 @@ func file_proto_events_proto_init() {
 	}
 	if !protoimpl.UnsafeEnabled {
 		file_proto_events_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Event); i {
+			switch v := v.(*LimiterSettings); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -527,7 +2631,7 @@ func file_proto_events_proto_init() {
 			}
 		}
 		file_proto_events_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EventReply); i {
+			switch v := v.(*DuckingSettings); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -539,7 +2643,7 @@ func file_proto_events_proto_init() {
 			}
 		}
 		file_proto_events_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RecordRequest); i {
+			switch v := v.(*Event); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -551,7 +2655,7 @@ func file_proto_events_proto_init() {
 			}
 		}
 		file_proto_events_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RecordReply); i {
+			switch v := v.(*EventReply); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -563,7 +2667,7 @@ func file_proto_events_proto_init() {
 			}
 		}
 		file_proto_events_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StopRequest); i {
+			switch v := v.(*EventAck); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -575,6 +2679,66 @@ func file_proto_events_proto_init() {
 			}
 		}
 		file_proto_events_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutputProfile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MixFormat); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StopRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StopReply); i {
 			case 0:
 				return &v.state
@@ -586,14 +2750,199 @@ func file_proto_events_proto_init() {
 				return nil
 			}
 		}
+		file_proto_events_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Status); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TrackInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RecordInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRecordsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListRecordsReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRecordRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RenderRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RenderReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PauseRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PauseReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResumeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResumeReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RerenderRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_proto_events_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RerenderReply); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_proto_events_proto_msgTypes[2].OneofWrappers = []interface{}{
+		(*Event_AtRecordMs)(nil),
+		(*Event_AtUnixMs)(nil),
 	}
+	file_proto_events_proto_msgTypes[13].OneofWrappers = []interface{}{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_proto_events_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   6,
+			NumEnums:      5,
+			NumMessages:   26,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -24,8 +24,24 @@ const _ = grpc.SupportPackageIsVersion7
 type EventStreamClient interface {
 	// Stream of events.
 	StreamEvents(ctx context.Context, opts ...grpc.CallOption) (EventStream_StreamEventsClient, error)
+	// Stream of events, each one being acknowledged with the result of its handling
+	StreamEventsAck(ctx context.Context, opts ...grpc.CallOption) (EventStream_StreamEventsAckClient, error)
 	Start(ctx context.Context, in *RecordRequest, opts ...grpc.CallOption) (*RecordReply, error)
 	Stop(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*StopReply, error)
+	// Pauses a whole record, nothing is written to the record file until it is resumed
+	Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseReply, error)
+	// Resumes a paused record, right where it was paused
+	Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeReply, error)
+	// Renders a stopped record again from its journal, faster than real time
+	Rerender(ctx context.Context, in *RerenderRequest, opts ...grpc.CallOption) (*RerenderReply, error)
+	// Renders a whole timeline at once, faster than real time
+	Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderReply, error)
+	// Notifications about a running record, until it is stopped and uploaded
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (EventStream_WatchClient, error)
+	// State of every running record
+	ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsReply, error)
+	// State of a running record
+	GetRecord(ctx context.Context, in *GetRecordRequest, opts ...grpc.CallOption) (*RecordInfo, error)
 }
 
 type eventStreamClient struct {
@@ -70,6 +86,37 @@ func (x *eventStreamStreamEventsClient) CloseAndRecv() (*EventReply, error) {
 	return m, nil
 }
 
+func (c *eventStreamClient) StreamEventsAck(ctx context.Context, opts ...grpc.CallOption) (EventStream_StreamEventsAckClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventStream_ServiceDesc.Streams[1], "/events.EventStream/StreamEventsAck", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventStreamStreamEventsAckClient{stream}
+	return x, nil
+}
+
+type EventStream_StreamEventsAckClient interface {
+	Send(*Event) error
+	Recv() (*EventAck, error)
+	grpc.ClientStream
+}
+
+type eventStreamStreamEventsAckClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventStreamStreamEventsAckClient) Send(m *Event) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *eventStreamStreamEventsAckClient) Recv() (*EventAck, error) {
+	m := new(EventAck)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *eventStreamClient) Start(ctx context.Context, in *RecordRequest, opts ...grpc.CallOption) (*RecordReply, error) {
 	out := new(RecordReply)
 	err := c.cc.Invoke(ctx, "/events.EventStream/Start", in, out, opts...)
@@ -88,14 +135,116 @@ func (c *eventStreamClient) Stop(ctx context.Context, in *StopRequest, opts ...g
 	return out, nil
 }
 
+func (c *eventStreamClient) Pause(ctx context.Context, in *PauseRequest, opts ...grpc.CallOption) (*PauseReply, error) {
+	out := new(PauseReply)
+	err := c.cc.Invoke(ctx, "/events.EventStream/Pause", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStreamClient) Resume(ctx context.Context, in *ResumeRequest, opts ...grpc.CallOption) (*ResumeReply, error) {
+	out := new(ResumeReply)
+	err := c.cc.Invoke(ctx, "/events.EventStream/Resume", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStreamClient) Rerender(ctx context.Context, in *RerenderRequest, opts ...grpc.CallOption) (*RerenderReply, error) {
+	out := new(RerenderReply)
+	err := c.cc.Invoke(ctx, "/events.EventStream/Rerender", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStreamClient) Render(ctx context.Context, in *RenderRequest, opts ...grpc.CallOption) (*RenderReply, error) {
+	out := new(RenderReply)
+	err := c.cc.Invoke(ctx, "/events.EventStream/Render", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStreamClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (EventStream_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &EventStream_ServiceDesc.Streams[2], "/events.EventStream/Watch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &eventStreamWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type EventStream_WatchClient interface {
+	Recv() (*Status, error)
+	grpc.ClientStream
+}
+
+type eventStreamWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *eventStreamWatchClient) Recv() (*Status, error) {
+	m := new(Status)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *eventStreamClient) ListRecords(ctx context.Context, in *ListRecordsRequest, opts ...grpc.CallOption) (*ListRecordsReply, error) {
+	out := new(ListRecordsReply)
+	err := c.cc.Invoke(ctx, "/events.EventStream/ListRecords", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventStreamClient) GetRecord(ctx context.Context, in *GetRecordRequest, opts ...grpc.CallOption) (*RecordInfo, error) {
+	out := new(RecordInfo)
+	err := c.cc.Invoke(ctx, "/events.EventStream/GetRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EventStreamServer is the server API for EventStream service.
 // All implementations must embed UnimplementedEventStreamServer
 // for forward compatibility
 type EventStreamServer interface {
 	// Stream of events.
 	StreamEvents(EventStream_StreamEventsServer) error
+	// Stream of events, each one being acknowledged with the result of its handling
+	StreamEventsAck(EventStream_StreamEventsAckServer) error
 	Start(context.Context, *RecordRequest) (*RecordReply, error)
 	Stop(context.Context, *StopRequest) (*StopReply, error)
+	// Pauses a whole record, nothing is written to the record file until it is resumed
+	Pause(context.Context, *PauseRequest) (*PauseReply, error)
+	// Resumes a paused record, right where it was paused
+	Resume(context.Context, *ResumeRequest) (*ResumeReply, error)
+	// Renders a stopped record again from its journal, faster than real time
+	Rerender(context.Context, *RerenderRequest) (*RerenderReply, error)
+	// Renders a whole timeline at once, faster than real time
+	Render(context.Context, *RenderRequest) (*RenderReply, error)
+	// Notifications about a running record, until it is stopped and uploaded
+	Watch(*WatchRequest, EventStream_WatchServer) error
+	// State of every running record
+	ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsReply, error)
+	// State of a running record
+	GetRecord(context.Context, *GetRecordRequest) (*RecordInfo, error)
 	mustEmbedUnimplementedEventStreamServer()
 }
 
@@ -106,12 +255,36 @@ type UnimplementedEventStreamServer struct {
 func (UnimplementedEventStreamServer) StreamEvents(EventStream_StreamEventsServer) error {
 	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
 }
+func (UnimplementedEventStreamServer) StreamEventsAck(EventStream_StreamEventsAckServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEventsAck not implemented")
+}
 func (UnimplementedEventStreamServer) Start(context.Context, *RecordRequest) (*RecordReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
 }
 func (UnimplementedEventStreamServer) Stop(context.Context, *StopRequest) (*StopReply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
 }
+func (UnimplementedEventStreamServer) Pause(context.Context, *PauseRequest) (*PauseReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Pause not implemented")
+}
+func (UnimplementedEventStreamServer) Resume(context.Context, *ResumeRequest) (*ResumeReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Resume not implemented")
+}
+func (UnimplementedEventStreamServer) Rerender(context.Context, *RerenderRequest) (*RerenderReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Rerender not implemented")
+}
+func (UnimplementedEventStreamServer) Render(context.Context, *RenderRequest) (*RenderReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Render not implemented")
+}
+func (UnimplementedEventStreamServer) Watch(*WatchRequest, EventStream_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedEventStreamServer) ListRecords(context.Context, *ListRecordsRequest) (*ListRecordsReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRecords not implemented")
+}
+func (UnimplementedEventStreamServer) GetRecord(context.Context, *GetRecordRequest) (*RecordInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRecord not implemented")
+}
 func (UnimplementedEventStreamServer) mustEmbedUnimplementedEventStreamServer() {}
 
 // UnsafeEventStreamServer may be embedded to opt out of forward compatibility for this service.
@@ -151,6 +324,32 @@ func (x *eventStreamStreamEventsServer) Recv() (*Event, error) {
 	return m, nil
 }
 
+func _EventStream_StreamEventsAck_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventStreamServer).StreamEventsAck(&eventStreamStreamEventsAckServer{stream})
+}
+
+type EventStream_StreamEventsAckServer interface {
+	Send(*EventAck) error
+	Recv() (*Event, error)
+	grpc.ServerStream
+}
+
+type eventStreamStreamEventsAckServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventStreamStreamEventsAckServer) Send(m *EventAck) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *eventStreamStreamEventsAckServer) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _EventStream_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RecordRequest)
 	if err := dec(in); err != nil {
@@ -187,6 +386,135 @@ func _EventStream_Stop_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventStream_Pause_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStreamServer).Pause(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.EventStream/Pause",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStreamServer).Pause(ctx, req.(*PauseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStream_Resume_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResumeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStreamServer).Resume(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.EventStream/Resume",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStreamServer).Resume(ctx, req.(*ResumeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStream_Rerender_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RerenderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStreamServer).Rerender(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.EventStream/Rerender",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStreamServer).Rerender(ctx, req.(*RerenderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStream_Render_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStreamServer).Render(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.EventStream/Render",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStreamServer).Render(ctx, req.(*RenderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStream_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventStreamServer).Watch(m, &eventStreamWatchServer{stream})
+}
+
+type EventStream_WatchServer interface {
+	Send(*Status) error
+	grpc.ServerStream
+}
+
+type eventStreamWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *eventStreamWatchServer) Send(m *Status) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _EventStream_ListRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStreamServer).ListRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.EventStream/ListRecords",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStreamServer).ListRecords(ctx, req.(*ListRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventStream_GetRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventStreamServer).GetRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/events.EventStream/GetRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventStreamServer).GetRecord(ctx, req.(*GetRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // EventStream_ServiceDesc is the grpc.ServiceDesc for EventStream service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -202,6 +530,30 @@ var EventStream_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Stop",
 			Handler:    _EventStream_Stop_Handler,
 		},
+		{
+			MethodName: "Pause",
+			Handler:    _EventStream_Pause_Handler,
+		},
+		{
+			MethodName: "Resume",
+			Handler:    _EventStream_Resume_Handler,
+		},
+		{
+			MethodName: "Rerender",
+			Handler:    _EventStream_Rerender_Handler,
+		},
+		{
+			MethodName: "Render",
+			Handler:    _EventStream_Render_Handler,
+		},
+		{
+			MethodName: "ListRecords",
+			Handler:    _EventStream_ListRecords_Handler,
+		},
+		{
+			MethodName: "GetRecord",
+			Handler:    _EventStream_GetRecord_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -209,6 +561,17 @@ var EventStream_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _EventStream_StreamEvents_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "StreamEventsAck",
+			Handler:       _EventStream_StreamEventsAck_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Watch",
+			Handler:       _EventStream_Watch_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "proto/events.proto",
 }
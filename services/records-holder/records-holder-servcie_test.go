@@ -1,9 +1,16 @@
 package records_holder
 
 import (
+	"bytes"
+	"fmt"
+	"github.com/faiface/beep"
 	"github.com/stretchr/testify/assert"
+	"live-audio-mixer/pkg/recorder"
+	pb "live-audio-mixer/proto"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func teardown(t *testing.T) {
@@ -14,12 +21,12 @@ func teardown(t *testing.T) {
 }
 func TestRecordsHolder_Record(t *testing.T) {
 	defer teardown(t)
-	rh := NewRecordsHolder(nil)
-	err := rh.Record("1")
+	rh := NewRecordsHolder(nil, nil, nil)
+	err := rh.Record("1", nil, nil)
 	assert.NoError(t, err)
-	err = rh.Record("2")
+	err = rh.Record("2", nil, nil)
 	assert.NoError(t, err)
-	err = rh.Record("1")
+	err = rh.Record("1", nil, nil)
 	assert.Error(t, err)
 	err = rh.Stop("1")
 	assert.NoError(t, err)
@@ -29,3 +36,247 @@ func TestRecordsHolder_Record(t *testing.T) {
 	assert.NoError(t, err)
 
 }
+
+func TestRecordsHolder_RecordProfile(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	err := rh.Record("mp3", &pb.OutputProfile{Codec: pb.Codec_MP3, BitrateKbps: 192}, nil)
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(baseDir, "mp3", "rec.mp3"))
+	err = rh.Stop("mp3")
+	assert.NoError(t, err)
+
+	err = rh.Record("invalid", &pb.OutputProfile{Codec: pb.Codec_WAV, Container: "ogg"}, nil)
+	assert.Error(t, err)
+	err = rh.Stop("invalid")
+	assert.Error(t, err)
+}
+
+func TestRecordsHolder_RecordInProcess(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	err := rh.Record("master", &pb.OutputProfile{Codec: pb.Codec_FLAC, InProcess: true}, nil)
+	assert.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	err = rh.Stop("master")
+	assert.NoError(t, err)
+	// The file is complete once the record is stopped
+	content, err := os.ReadFile(filepath.Join(baseDir, "master", "rec.flac"))
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(content, []byte("fLaC")))
+	assert.Greater(t, len(content), 42)
+
+	err = rh.Record("invalid", &pb.OutputProfile{Codec: pb.Codec_OPUS, InProcess: true}, nil)
+	assert.Error(t, err)
+}
+
+func TestRecordsHolder_RecordMixFormat(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	err := rh.Record("podcast", nil, &pb.MixFormat{SampleRate: 44100, Channels: 1})
+	assert.NoError(t, err)
+	err = rh.Stop("podcast")
+	assert.NoError(t, err)
+
+	err = rh.Record("low-latency", nil, &pb.MixFormat{BlockMs: 5})
+	assert.NoError(t, err)
+	err = rh.Stop("low-latency")
+	assert.NoError(t, err)
+
+	for _, mix := range []*pb.MixFormat{{SampleRate: 1000}, {Channels: 6}, {BitDepth: 32}, {BlockMs: 500}} {
+		err = rh.Record("invalid", nil, mix)
+		assert.Error(t, err)
+	}
+}
+
+func TestRecordsHolder_Watch(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	_, _, err := rh.Watch("watched")
+	assert.Error(t, err)
+	err = rh.Record("watched", &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, nil)
+	assert.NoError(t, err)
+	statuses, unsubscribe, err := rh.Watch("watched")
+	assert.NoError(t, err)
+	defer unsubscribe()
+	err = rh.Update(&pb.Event{RecordId: "watched", Type: pb.EventType_STOP, TrackId: "unknown"})
+	assert.Error(t, err)
+	err = rh.Stop("watched")
+	assert.NoError(t, err)
+
+	var received []pb.StatusType
+	for status := range statuses {
+		assert.Equal(t, "watched", status.RecordId)
+		received = append(received, status.Type)
+	}
+	assert.Equal(t, []pb.StatusType{pb.StatusType_EVENT_ERROR, pb.StatusType_RECORD_STOPPED}, received)
+}
+
+func TestRecordsHolder_ListRecords(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	assert.Empty(t, rh.ListRecords())
+	wav := &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}
+	assert.NoError(t, rh.Record("b", wav, nil))
+	assert.NoError(t, rh.Record("a", wav, nil))
+	time.Sleep(50 * time.Millisecond)
+	records := rh.ListRecords()
+	assert.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].Id)
+	assert.Equal(t, "b", records[1].Id)
+	assert.Greater(t, records[1].ElapsedMs, int64(0))
+	assert.Empty(t, records[1].Tracks)
+
+	record, err := rh.GetRecord("a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", record.Id)
+	assert.LessOrEqual(t, record.StartUnixMs, time.Now().UnixMilli())
+	assert.True(t, record.Ducking.Enabled)
+	assert.NoError(t, rh.Stop("a"))
+	_, err = rh.GetRecord("a")
+	assert.ErrorIs(t, err, ErrRecordNotFound)
+	assert.NoError(t, rh.Stop("b"))
+}
+
+func TestRecordsHolder_Pause(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	assert.ErrorIs(t, rh.Pause("paused"), ErrRecordNotFound)
+	assert.ErrorIs(t, rh.Resume("paused"), ErrRecordNotFound)
+	err := rh.Record("paused", &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, nil)
+	assert.NoError(t, err)
+	statuses, unsubscribe, err := rh.Watch("paused")
+	assert.NoError(t, err)
+	defer unsubscribe()
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, rh.Pause("paused"))
+	assert.Error(t, rh.Pause("paused"))
+	record, err := rh.GetRecord("paused")
+	assert.NoError(t, err)
+	assert.True(t, record.Paused)
+	// The pause doesn't show in the record file
+	time.Sleep(500 * time.Millisecond)
+	assert.NoError(t, rh.Resume("paused"))
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, rh.Stop("paused"))
+	info, err := os.Stat(filepath.Join(baseDir, "paused", "rec.wav"))
+	assert.NoError(t, err)
+	duration := time.Duration(info.Size()-44) * time.Second / (48000 * 4)
+	assert.InDelta(t, 200*time.Millisecond, duration, float64(100*time.Millisecond))
+
+	assert.Equal(t, pb.StatusType_RECORD_PAUSED, (<-statuses).Type)
+	assert.Equal(t, pb.StatusType_RECORD_RESUMED, (<-statuses).Type)
+}
+
+func TestRecordsHolder_Rerender(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	flac := &pb.OutputProfile{Codec: pb.Codec_FLAC, InProcess: true}
+	err := rh.Record("session", flac, nil)
+	assert.NoError(t, err)
+	_, err = rh.Rerender("session", nil, nil)
+	assert.Error(t, err)
+	time.Sleep(200 * time.Millisecond)
+	err = rh.Stop("session")
+	assert.NoError(t, err)
+	assert.FileExists(t, filepath.Join(baseDir, "session", journalName))
+
+	original, err := os.ReadFile(filepath.Join(baseDir, "session", "rec.flac"))
+	assert.NoError(t, err)
+
+	// The record is rendered again in another format, with the same duration
+	path, err := rh.Rerender("session", &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, &pb.MixFormat{SampleRate: 44100})
+	assert.NoError(t, err)
+	assert.Regexp(t, `^rec\.rerender-\d+\.wav$`, filepath.Base(path))
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(content, []byte("RIFF")))
+	assert.Greater(t, len(content), 44+44100*4/10)
+	// Rendering again in the same format leaves the original record file as is
+	path, err = rh.Rerender("session", flac, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, filepath.Join(baseDir, "session", "rec.flac"), path)
+	rendered, err := os.ReadFile(filepath.Join(baseDir, "session", "rec.flac"))
+	assert.NoError(t, err)
+	assert.Equal(t, original, rendered)
+
+	_, err = rh.Rerender("unknown", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestRecordsHolder_Render(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	events := []*pb.Event{{Type: pb.EventType_OTHER, TrackId: "a", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 100}}}
+	// A whole minute is rendered much faster than real time
+	started := time.Now()
+	path, err := rh.Render("edited", events, time.Minute, &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, &pb.MixFormat{Channels: 1})
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(started), 30*time.Second)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(44+48000*60*2), info.Size())
+
+	_, err = rh.Render("unpositioned", []*pb.Event{{Type: pb.EventType_OTHER}}, 0, nil, nil)
+	assert.Error(t, err)
+	// Events which can't be applied fail the render
+	missing := []*pb.Event{{Type: pb.EventType_PLAY, AssetUrl: "file:///missing.flac", ApplyAt: &pb.Event_AtRecordMs{AtRecordMs: 100}}}
+	_, err = rh.Render("broken", missing, time.Second, &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, nil)
+	assert.Equal(t, pb.ErrorCode_LOAD_FAILED, ErrorCode(err))
+	files, err := filepath.Glob(filepath.Join(baseDir, "broken", "*"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+	err = rh.Record("running", nil, nil)
+	assert.NoError(t, err)
+	_, err = rh.Render("running", events, 0, nil, nil)
+	assert.Error(t, err)
+	assert.NoError(t, rh.Stop("running"))
+	// Rendering under the id of a stopped record leaves its files as is
+	files, err = filepath.Glob(filepath.Join(baseDir, "running", "*"))
+	assert.NoError(t, err)
+	path, err = rh.Render("running", events, time.Second, &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, files, path)
+	for _, file := range files {
+		assert.FileExists(t, file)
+	}
+
+	// A record being rendered can neither be rendered again nor recorded until the render is over
+	_, release, err := rh.offlineDir("rendering")
+	assert.NoError(t, err)
+	_, err = rh.Render("rendering", events, 0, nil, nil)
+	assert.Error(t, err)
+	_, err = rh.Rerender("rendering", nil, nil)
+	assert.Error(t, err)
+	assert.Error(t, rh.Record("rendering", nil, nil))
+	release()
+	_, err = rh.Render("rendering", events, 0, &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, nil)
+	assert.NoError(t, err)
+}
+
+func TestRecordsHolder_UpdateErrors(t *testing.T) {
+	defer teardown(t)
+	rh := NewRecordsHolder(nil, nil, nil)
+	err := rh.Update(&pb.Event{RecordId: "unknown", Type: pb.EventType_STOP, TrackId: "a"})
+	assert.Equal(t, pb.ErrorCode_RECORD_NOT_FOUND, ErrorCode(err))
+	err = rh.Record("errors", &pb.OutputProfile{Codec: pb.Codec_WAV, InProcess: true}, nil)
+	assert.NoError(t, err)
+	err = rh.Update(&pb.Event{RecordId: "errors", Type: pb.EventType_STOP, TrackId: "a"})
+	assert.Equal(t, pb.ErrorCode_TRACK_NOT_FOUND, ErrorCode(err))
+	err = rh.Update(&pb.Event{RecordId: "errors", TrackId: "a"})
+	assert.Equal(t, pb.ErrorCode_INVALID_EVENT, ErrorCode(err))
+	assert.NoError(t, rh.Stop("errors"))
+
+	assert.Equal(t, pb.ErrorCode_OK, ErrorCode(nil))
+	assert.Equal(t, pb.ErrorCode_LOAD_FAILED, ErrorCode(fmt.Errorf("%w: not an audio file", recorder.ErrLoad)))
+	assert.Equal(t, pb.ErrorCode_INTERNAL, ErrorCode(fmt.Errorf("unexpected")))
+}
+
+func TestRecordsHolder_toFormat(t *testing.T) {
+	format, err := toFormat(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, recorder.DefaultFormat, format)
+	format, err = toFormat(&pb.MixFormat{SampleRate: 44100, Channels: 1, BitDepth: 24})
+	assert.NoError(t, err)
+	assert.Equal(t, beep.Format{SampleRate: 44100, NumChannels: 1, Precision: 3}, format)
+}
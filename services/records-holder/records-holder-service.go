@@ -1,46 +1,114 @@
 package records_holder
 
 import (
+	"errors"
 	"fmt"
+	"github.com/faiface/beep"
+	"google.golang.org/protobuf/proto"
+	disc_jockey "live-audio-mixer/internal/disc-jockey"
+	live_stream "live-audio-mixer/internal/live-stream"
+	"live-audio-mixer/internal/loudness"
 	rt_encoder "live-audio-mixer/internal/rt-encoder"
+	status_feed "live-audio-mixer/internal/status-feed"
 	stream_handler "live-audio-mixer/internal/stream-handler"
 	"live-audio-mixer/pkg/recorder"
 	pb "live-audio-mixer/proto"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
+	"sync"
+	"time"
 )
 
 const (
 	baseDir = "./rec/"
-	dstName = "rec.ogg"
+	// Name of the record file, without extension
+	dstName = "rec"
+	// Name of the journal of the events applied to each record
+	journalName = "journal.jsonl"
+	// Names of the sinks of each record
+	fileSink = "file"
+	liveSink = "live"
 )
 
+// ErrRecordNotFound is returned when targeting a record that is not running
+var ErrRecordNotFound = errors.New("record does not exist")
+
 type ObjectStorage interface {
 	Upload(path string, id string) error
+	Download(id string, path string) error
 }
 type RecordsHolder struct {
 	records map[string]*Record
 	store   ObjectStorage
+	// Loudness of the assets played with a target loudness, shared by every record and render
+	loudness *loudness.Cache
+	// Transcoded assets, shared by every record and render. nil when assets are not cached
+	assets *stream_handler.AssetCache
+	// Where assets are fetched from, by URL scheme
+	sources stream_handler.Sources
+	// Ids of the records being rendered offline, whose directory is in use
+	rendering map[string]bool
+	// Records are accessed both by the gRPC server and the live streaming server
+	mu sync.Mutex
 }
 
 type Record struct {
 	rec *recorder.Recorder
 	dir string
 	dst *os.File
+	// Every event applied to the mix, to render it again later on
+	journal *os.File
+	// Receive the result of the encoding of the record file, and of the live stream, once it is over
+	fileDone, liveDone chan error
+	// Encoding of the record file
+	profile rt_encoder.Profile
+	// Live stream of the record, for listeners to hear the mix while it is being recorded
+	live *live_stream.Broadcaster
+	// Notifications about the record, for clients to follow its progress
+	feed *status_feed.Feed
 }
 
-func NewRecordsHolder(store ObjectStorage) *RecordsHolder {
+// NewRecordsHolder creates a new records holder. Records are uploaded to store, and the assets they play
+// are fetched from sources and cached in assets. All are optional, sources defaulting to public assets only
+func NewRecordsHolder(store ObjectStorage, assets *stream_handler.AssetCache, sources stream_handler.Sources) *RecordsHolder {
+	if sources == nil {
+		sources = stream_handler.DefaultSources()
+	}
 	return &RecordsHolder{
-		records: map[string]*Record{},
-		store:   store,
+		records:   map[string]*Record{},
+		store:     store,
+		loudness:  loudness.NewCache(0),
+		assets:    assets,
+		sources:   sources,
+		rendering: map[string]bool{},
 	}
 }
 
-func (rh *RecordsHolder) Record(id string) error {
+// Record starts a new record. The output profile defines how the record file is encoded, nil meaning the default profile.
+// The mix format is used throughout the whole chain, nil meaning the default format
+func (rh *RecordsHolder) Record(id string, output *pb.OutputProfile, mix *pb.MixFormat) error {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
 	if rh.hasRecord(id) {
 		return fmt.Errorf("record with id %s already exists", id)
 	}
+	if rh.rendering[id] {
+		return fmt.Errorf("record with id %s is being rendered", id)
+	}
+	profile, err := toProfile(output)
+	if err != nil {
+		return err
+	}
+	format, err := toFormat(mix)
+	if err != nil {
+		return err
+	}
+	block, err := toBlock(mix)
+	if err != nil {
+		return err
+	}
 	absPath, err := filepath.Abs(baseDir)
 	if err != nil {
 		return err
@@ -50,38 +118,104 @@ func (rh *RecordsHolder) Record(id string) error {
 	if err != nil {
 		return err
 	}
-	dst, err := os.Create(filepath.Join(dir, dstName))
+	dst, err := os.Create(filepath.Join(dir, dstName+"."+profile.Extension()))
+	if err != nil {
+		return err
+	}
+	journalFile, err := os.Create(filepath.Join(dir, journalName))
 	if err != nil {
+		dst.Close()
+		return err
+	}
+	journal, err := recorder.NewJournal(journalFile, format.SampleRate)
+	if err != nil {
+		dst.Close()
+		journalFile.Close()
 		return err
 	}
 
-	rh.records[id] = &Record{
-		rec: recorder.NewRecorder(stream_handler.NewHandler(), rt_encoder.FFEncode),
-		dir: dir,
-		dst: dst,
+	feed := status_feed.NewFeed()
+	opt := recorder.RecorderOpt{
+		Block:    block,
+		Journal:  journal,
+		Loudness: rh.loudness,
+		OnStatus: func(status *pb.Status) {
+			status.RecordId = id
+			feed.Publish(status)
+		},
+	}
+	record := &Record{
+		rec:     recorder.NewRecorder(stream_handler.NewCachedHandler(format, rh.assets, rh.sources), format, opt),
+		dir:     dir,
+		dst:     dst,
+		journal: journalFile,
+		profile: profile,
+		live:    live_stream.NewBroadcaster(),
+		feed:    feed,
 	}
-	rh.records[id].rec.Start(dst)
+	// The mix is both written to the file and broadcast live, each with its own encoder.
+	// The live stream is always Opus in Ogg, as this is what the broadcaster expects
+	record.fileDone, err = record.rec.AddSink(fileSink, dst, rt_encoder.NewEncoder(profile))
+	if err == nil {
+		record.liveDone, err = record.rec.AddSink(liveSink, record.live, rt_encoder.FFEncode)
+	}
+	if err != nil {
+		record.rec.Stop()
+		// The file encoder, if started, is done with the file before it is closed
+		if record.fileDone != nil {
+			<-record.fileDone
+		}
+		record.live.Close()
+		feed.Close()
+		dst.Close()
+		journalFile.Close()
+		return err
+	}
+	rh.records[id] = record
 	return nil
 }
 
 func (rh *RecordsHolder) Stop(id string) error {
+	rh.mu.Lock()
 	if !rh.hasRecord(id) {
-		return fmt.Errorf("Record with id %s does not exist", id)
+		rh.mu.Unlock()
+		return recordNotFound(id)
 	}
 	record := rh.records[id]
+	delete(rh.records, id)
+	rh.mu.Unlock()
+	// Watchers are released once the record is over, whatever happens
+	defer record.feed.Close()
 	record.rec.Stop()
+	// The live encoder is done writing before listeners are dropped, its errors have already been logged by the recorder
+	<-record.liveDone
+	record.live.Close()
+	// The encoder may still be finalizing the file, errors have already been logged by the recorder
+	stopped := &pb.Status{RecordId: id, Type: pb.StatusType_RECORD_STOPPED}
+	if err := <-record.fileDone; err != nil {
+		stopped.Error = err.Error()
+	}
 	err := record.dst.Close()
 	if err != nil {
 		return err
 	}
-	delete(rh.records, id)
-	// Optionally, upload the file to the object storage
+	err = record.journal.Close()
+	if err != nil {
+		return err
+	}
+	record.feed.Publish(stopped)
+	// Optionally, upload the file and its journal to the object storage
 	if rh.store != nil {
-		recordPath := filepath.Join(record.dir, dstName)
-		err = rh.store.Upload(recordPath, fmt.Sprintf("%s.ogg", id))
+		key := fmt.Sprintf("%s.%s", id, record.profile.Extension())
+		err = rh.store.Upload(record.dst.Name(), key)
+		if err == nil {
+			err = rh.store.Upload(record.journal.Name(), journalKey(id))
+		}
 		if err != nil {
+			record.feed.Publish(&pb.Status{RecordId: id, Type: pb.StatusType_UPLOAD_ERROR, Error: err.Error()})
 			return err
 		}
+		record.feed.Publish(&pb.Status{RecordId: id, Type: pb.StatusType_UPLOAD_FINISHED, Key: key})
 		err = os.RemoveAll(record.dir)
 		if err != nil {
 			slog.Warn(fmt.Sprintf("[RecordsHolder] :: Error while removing record dir %s : %v", record.dir, err))
@@ -90,14 +224,391 @@ func (rh *RecordsHolder) Stop(id string) error {
 	return nil
 }
 
-func (rh *RecordsHolder) Update(event *pb.Event) error {
-	if !rh.hasRecord(event.RecordId) {
-		return fmt.Errorf("Record with id %s does not exist", event.RecordId)
+// Rerender renders a stopped record again from its journal, as fast as possible, replaying the events against the same assets.
+// This allows regenerating a record with another output profile or mix format, or fixing a corrupted record file.
+// The original record file is left as is, the new one being suffixed with .rerender-<unix time in ms>.
+// It returns the location of the new record file: its key in the object storage, or its path without storage
+func (rh *RecordsHolder) Rerender(id string, output *pb.OutputProfile, mix *pb.MixFormat) (string, error) {
+	dir, release, err := rh.offlineDir(id)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	timeline, err := rh.readJournal(id, dir)
+	if err != nil {
+		return "", err
+	}
+	if !timeline.Complete {
+		slog.Warn(fmt.Sprintf("[RecordsHolder] :: Journal of record %s is incomplete, rendering up to its last event", id))
+	}
+	return rh.render(id, dir, fmt.Sprintf(".rerender-%d", time.Now().UnixMilli()), timeline, output, mix)
+}
+
+// Render mixes a whole timeline at once, as fast as possible. Every event must be positioned with atRecordMs.
+// The mix lasts for the given duration, or up to the last event when zero.
+// The id may be the one of an earlier record, whose file is left as is: the record file is suffixed with
+// .render-<unix time in ms>. It returns its location: its key in the object storage, or its path without storage
+func (rh *RecordsHolder) Render(id string, events []*pb.Event, duration time.Duration, output *pb.OutputProfile, mix *pb.MixFormat) (string, error) {
+	dir, release, err := rh.offlineDir(id)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	format, err := toFormat(mix)
+	if err != nil {
+		return "", err
+	}
+	timeline, err := recorder.NewTimeline(events, format.SampleRate, duration)
+	if err != nil {
+		return "", err
+	}
+	return rh.render(id, dir, fmt.Sprintf(".render-%d", time.Now().UnixMilli()), timeline, output, mix)
+}
+
+// offlineDir returns the directory of a record rendered offline, which must be neither running nor being rendered.
+// The directory is reserved for the render until release is called
+func (rh *RecordsHolder) offlineDir(id string) (dir string, release func(), err error) {
+	absPath, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", nil, err
+	}
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	if rh.hasRecord(id) {
+		return "", nil, fmt.Errorf("record with id %s is still running", id)
+	}
+	if rh.rendering[id] {
+		return "", nil, fmt.Errorf("record with id %s is already being rendered", id)
+	}
+	rh.rendering[id] = true
+	release = func() {
+		rh.mu.Lock()
+		defer rh.mu.Unlock()
+		delete(rh.rendering, id)
+	}
+	return filepath.Join(absPath, id), release, nil
+}
+
+// render mixes a timeline to the record file in dir, and uploads it when there is an object storage.
+// suffix is added to the name of the record file and to its key. The mix is rendered to a temporary file,
+// which only replaces the record file once the render succeeded
+func (rh *RecordsHolder) render(id string, dir string, suffix string, timeline *recorder.Timeline, output *pb.OutputProfile, mix *pb.MixFormat) (string, error) {
+	profile, err := toProfile(output)
+	if err != nil {
+		return "", err
+	}
+	format, err := toFormat(mix)
+	if err != nil {
+		return "", err
+	}
+	block, err := toBlock(mix)
+	if err != nil {
+		return "", err
+	}
+	err = os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return "", err
+	}
+	dst, err := os.CreateTemp(dir, dstName+suffix+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		// Left over only when the render failed, or once uploaded
+		if err := os.Remove(dst.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn(fmt.Sprintf("[RecordsHolder] :: Error while removing rendered file %s : %v", dst.Name(), err))
+		}
+	}()
+	err = recorder.Render(stream_handler.NewCachedHandler(format, rh.assets, rh.sources), format, recorder.RecorderOpt{Block: block, Loudness: rh.loudness}, timeline, dst, rt_encoder.NewEncoder(profile))
+	if err != nil {
+		dst.Close()
+		return "", err
+	}
+	err = dst.Close()
+	if err != nil {
+		return "", err
+	}
+	if rh.store == nil {
+		path := filepath.Join(dir, dstName+suffix+"."+profile.Extension())
+		err = os.Rename(dst.Name(), path)
+		if err != nil {
+			return "", err
+		}
+		return path, nil
+	}
+	key := fmt.Sprintf("%s%s.%s", id, suffix, profile.Extension())
+	err = rh.store.Upload(dst.Name(), key)
+	if err != nil {
+		return "", err
+	}
+	// The directory may still hold files of the record, such as a record file which couldn't be uploaded,
+	// it is only removed once empty
+	_ = os.Remove(dir)
+	return key, nil
+}
+
+// readJournal reads the journal of a record, from its directory or else from the object storage
+func (rh *RecordsHolder) readJournal(id string, dir string) (*recorder.Timeline, error) {
+	path := filepath.Join(dir, journalName)
+	if _, err := os.Stat(path); err != nil {
+		if rh.store == nil {
+			return nil, fmt.Errorf("journal of record %s not found", id)
+		}
+		// Downloaded aside, so that the directory of the record only holds its own files
+		downloaded, err := os.CreateTemp("", "journal-*.jsonl")
+		if err != nil {
+			return nil, err
+		}
+		downloaded.Close()
+		defer os.Remove(downloaded.Name())
+		path = downloaded.Name()
+		err = rh.store.Download(journalKey(id), path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return recorder.ReadJournal(f)
+}
+
+// Pause freezes a whole record. Nothing is written to the record file and the tracks keep their position
+// until the record is resumed, so that breaks don't end up as silence in the file
+func (rh *RecordsHolder) Pause(id string) error {
+	rh.mu.Lock()
+	record, ok := rh.records[id]
+	rh.mu.Unlock()
+	if !ok {
+		return recordNotFound(id)
+	}
+	err := record.rec.Pause()
+	if err != nil {
+		return err
+	}
+	record.feed.Publish(&pb.Status{RecordId: id, Type: pb.StatusType_RECORD_PAUSED, PositionMs: record.rec.Elapsed().Milliseconds()})
+	return nil
+}
+
+// Resume continues a paused record seamlessly
+func (rh *RecordsHolder) Resume(id string) error {
+	rh.mu.Lock()
+	record, ok := rh.records[id]
+	rh.mu.Unlock()
+	if !ok {
+		return recordNotFound(id)
+	}
+	err := record.rec.Resume()
+	if err != nil {
+		return err
 	}
-	rh.records[event.RecordId].rec.Update(event)
+	record.feed.Publish(&pb.Status{RecordId: id, Type: pb.StatusType_RECORD_RESUMED, PositionMs: record.rec.Elapsed().Milliseconds()})
 	return nil
 }
 
+func (rh *RecordsHolder) Update(event *pb.Event) error {
+	rh.mu.Lock()
+	record, ok := rh.records[event.RecordId]
+	rh.mu.Unlock()
+	if !ok {
+		return recordNotFound(event.RecordId)
+	}
+	return record.rec.Update(event)
+}
+
+// Watch subscribes to the notifications about an ongoing record. The channel is closed once the record is stopped,
+// and uploaded when there is an object storage. The returned function must be called to unsubscribe
+func (rh *RecordsHolder) Watch(id string) (<-chan *pb.Status, func(), error) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	record, ok := rh.records[id]
+	if !ok {
+		return nil, nil, recordNotFound(id)
+	}
+	statuses, unsubscribe := record.feed.Subscribe()
+	return statuses, unsubscribe, nil
+}
+
+// ListRecords returns the state of every ongoing record, sorted by id
+func (rh *RecordsHolder) ListRecords() []*pb.RecordInfo {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	records := make([]*pb.RecordInfo, 0, len(rh.records))
+	for id, record := range rh.records {
+		records = append(records, toRecordInfo(id, record.rec))
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Id < records[j].Id })
+	return records
+}
+
+// GetRecord returns the state of an ongoing record
+func (rh *RecordsHolder) GetRecord(id string) (*pb.RecordInfo, error) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	record, ok := rh.records[id]
+	if !ok {
+		return nil, recordNotFound(id)
+	}
+	return toRecordInfo(id, record.rec), nil
+}
+
+// GetLiveStream returns the live stream of an ongoing record
+func (rh *RecordsHolder) GetLiveStream(id string) (*live_stream.Broadcaster, error) {
+	rh.mu.Lock()
+	defer rh.mu.Unlock()
+	record, ok := rh.records[id]
+	if !ok {
+		return nil, recordNotFound(id)
+	}
+	return record.live, nil
+}
+
+// Converts the state of a recorder to its API representation
+func toRecordInfo(id string, rec *recorder.Recorder) *pb.RecordInfo {
+	info := &pb.RecordInfo{
+		Id:          id,
+		StartUnixMs: rec.Started().UnixMilli(),
+		ElapsedMs:   rec.Elapsed().Milliseconds(),
+		Paused:      rec.Paused(),
+		LatencyMs:   rec.Latency().Milliseconds(),
+	}
+	master := rec.Master()
+	info.MasterGainDb = master.GainDb
+	info.Muted = master.Muted
+	info.Limiter = &pb.LimiterSettings{
+		Enabled:   master.Limiter.Enabled,
+		CeilingDb: master.Limiter.CeilingDb,
+		ReleaseMs: master.Limiter.Release.Milliseconds(),
+	}
+	info.Ducking = &pb.DuckingSettings{
+		Enabled:       master.Ducking.Enabled,
+		ThresholdDb:   master.Ducking.ThresholdDb,
+		AttenuationDb: master.Ducking.AttenuationDb,
+		AttackMs:      master.Ducking.Attack.Milliseconds(),
+		ReleaseMs:     master.Ducking.Release.Milliseconds(),
+	}
+	for _, track := range rec.Tracks() {
+		trackInfo := &pb.TrackInfo{
+			TrackId:    track.Id,
+			AssetUrl:   track.AssetUrl,
+			Paused:     track.Paused,
+			VolumeDb:   track.VolumeDb,
+			Loop:       track.Loop,
+			Loops:      int32(track.Loops),
+			PositionMs: track.Position.Milliseconds(),
+			Role:       trackRoles[track.Role],
+		}
+		if track.Length > 0 {
+			trackInfo.LengthMs = proto.Int64(track.Length.Milliseconds())
+			trackInfo.RemainingMs = proto.Int64(max(track.Length-track.Position, 0).Milliseconds())
+		}
+		info.Tracks = append(info.Tracks, trackInfo)
+	}
+	return info
+}
+
+// Roles of the tracks as exposed to clients
+var trackRoles = map[disc_jockey.Role]pb.TrackRole{
+	disc_jockey.RoleNone:   pb.TrackRole_ROLE_NONE,
+	disc_jockey.RoleMusic:  pb.TrackRole_ROLE_MUSIC,
+	disc_jockey.RoleEffect: pb.TrackRole_ROLE_EFFECT,
+	disc_jockey.RoleVoice:  pb.TrackRole_ROLE_VOICE,
+}
+
+// Converts the output profile of a request to an encoder profile
+func toProfile(output *pb.OutputProfile) (rt_encoder.Profile, error) {
+	if output == nil {
+		return rt_encoder.DefaultProfile, nil
+	}
+	codecs := map[pb.Codec]rt_encoder.Codec{
+		pb.Codec_OPUS: rt_encoder.Opus,
+		pb.Codec_MP3:  rt_encoder.Mp3,
+		pb.Codec_AAC:  rt_encoder.Aac,
+		pb.Codec_FLAC: rt_encoder.Flac,
+		pb.Codec_WAV:  rt_encoder.Wav,
+	}
+	codec, ok := codecs[output.Codec]
+	if !ok {
+		return rt_encoder.Profile{}, fmt.Errorf("unsupported codec %v", output.Codec)
+	}
+	return rt_encoder.Profile{
+		Codec:       codec,
+		BitrateKbps: int(output.BitrateKbps),
+		Container:   output.Container,
+		InProcess:   output.InProcess,
+	}.Validate()
+}
+
+// Converts the mix format of a request to a beep format, unset fields taking their default value
+func toFormat(mix *pb.MixFormat) (beep.Format, error) {
+	format := recorder.DefaultFormat
+	if mix == nil {
+		return format, nil
+	}
+	if mix.SampleRate != 0 {
+		if mix.SampleRate < 8000 || mix.SampleRate > 192000 {
+			return format, fmt.Errorf("unsupported sample rate %d Hz, must be between 8000 and 192000", mix.SampleRate)
+		}
+		format.SampleRate = beep.SampleRate(mix.SampleRate)
+	}
+	if mix.Channels != 0 {
+		if mix.Channels != 1 && mix.Channels != 2 {
+			return format, fmt.Errorf("unsupported number of channels %d, must be 1 or 2", mix.Channels)
+		}
+		format.NumChannels = int(mix.Channels)
+	}
+	if mix.BitDepth != 0 {
+		if mix.BitDepth != 8 && mix.BitDepth != 16 && mix.BitDepth != 24 {
+			return format, fmt.Errorf("unsupported bit depth %d, must be 8, 16 or 24", mix.BitDepth)
+		}
+		format.Precision = int(mix.BitDepth / 8)
+	}
+	return format, nil
+}
+
+// Extracts the duration of the mix blocks from the mix format of a request, 0 meaning the default duration
+func toBlock(mix *pb.MixFormat) (time.Duration, error) {
+	if mix == nil || mix.BlockMs == 0 {
+		return 0, nil
+	}
+	block := time.Duration(mix.BlockMs) * time.Millisecond
+	if block < recorder.MinBlock || block > recorder.MaxBlock {
+		return 0, fmt.Errorf("unsupported block duration %d ms, must be between %d and %d", mix.BlockMs, recorder.MinBlock.Milliseconds(), recorder.MaxBlock.Milliseconds())
+	}
+	return block, nil
+}
+
+func recordNotFound(id string) error {
+	return fmt.Errorf(`%w: "%s"`, ErrRecordNotFound, id)
+}
+
+// ErrorCode classifies the error returned when handling an event, for clients to react to it
+func ErrorCode(err error) pb.ErrorCode {
+	switch {
+	case err == nil:
+		return pb.ErrorCode_OK
+	case errors.Is(err, ErrRecordNotFound):
+		return pb.ErrorCode_RECORD_NOT_FOUND
+	case errors.Is(err, recorder.ErrTrackNotFound):
+		return pb.ErrorCode_TRACK_NOT_FOUND
+	case errors.Is(err, recorder.ErrTrackExists):
+		return pb.ErrorCode_TRACK_EXISTS
+	case errors.Is(err, recorder.ErrLoad):
+		return pb.ErrorCode_LOAD_FAILED
+	case errors.Is(err, recorder.ErrInvalidEvent):
+		return pb.ErrorCode_INVALID_EVENT
+	default:
+		return pb.ErrorCode_INTERNAL
+	}
+}
+
+// Key of the journal of a record in the object storage
+func journalKey(id string) string {
+	return fmt.Sprintf("%s.%s", id, journalName)
+}
+
 func (rh *RecordsHolder) hasRecord(id string) bool {
 	_, ok := rh.records[id]
 	return ok
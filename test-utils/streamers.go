@@ -0,0 +1,148 @@
+package test_utils
+
+import (
+	"errors"
+	"github.com/faiface/beep"
+	"math"
+)
+
+// ErrNotSeekable Returned when seeking a live test stream
+var ErrNotSeekable = errors.New("live streams can't be sought")
+
+// Constant A stream repeating the same sample, which can be changed between two calls.
+// It never ends when Length is 0
+type Constant struct {
+	Sample [2]float64
+	Length int
+	Pos    int
+	// Seeking a live stream fails, it must be opened again to start over
+	Live bool
+}
+
+// NewConstant Get an endless stream of the given value on both channels
+func NewConstant(value float64) *Constant {
+	return &Constant{Sample: [2]float64{value, value}}
+}
+
+func (c *Constant) Stream(samples [][2]float64) (n int, ok bool) {
+	n = available(len(samples), c.Length, c.Pos)
+	for i := range samples[:n] {
+		samples[i] = c.Sample
+	}
+	c.Pos += n
+	return n, n > 0
+}
+func (c *Constant) Err() error {
+	return nil
+}
+func (c *Constant) Len() int {
+	return c.Length
+}
+func (c *Constant) Position() int {
+	return c.Pos
+}
+func (c *Constant) Seek(p int) error {
+	return seek(&c.Pos, p, c.Live)
+}
+func (c *Constant) Close() error {
+	return nil
+}
+
+// Ramp A stream whose samples are their own position in the stream.
+// It never ends when Length is 0
+type Ramp struct {
+	Length int
+	Pos    int
+	// Seeking a live stream fails, it must be opened again to start over
+	Live bool
+}
+
+func (r *Ramp) Stream(samples [][2]float64) (n int, ok bool) {
+	n = available(len(samples), r.Length, r.Pos)
+	for i := range samples[:n] {
+		samples[i] = [2]float64{float64(r.Pos), float64(r.Pos)}
+		r.Pos++
+	}
+	return n, n > 0
+}
+func (r *Ramp) Err() error {
+	return nil
+}
+func (r *Ramp) Len() int {
+	return r.Length
+}
+func (r *Ramp) Position() int {
+	return r.Pos
+}
+func (r *Ramp) Seek(p int) error {
+	return seek(&r.Pos, p, r.Live)
+}
+func (r *Ramp) Close() error {
+	return nil
+}
+
+// Sine A sine of the given amplitude and frequency, which can be changed between two calls.
+// It never ends when Length is 0
+type Sine struct {
+	Amplitude  float64
+	Frequency  float64
+	SampleRate beep.SampleRate
+	Length     int
+	Pos        int
+	// The right channel is the opposite of the left one
+	OutOfPhase bool
+	// Seeking a live stream fails, it must be opened again to start over
+	Live bool
+}
+
+func (s *Sine) Stream(samples [][2]float64) (n int, ok bool) {
+	n = available(len(samples), s.Length, s.Pos)
+	for i := range samples[:n] {
+		v := s.Amplitude * math.Sin(2*math.Pi*s.Frequency*float64(s.Pos)/float64(s.SampleRate))
+		if s.OutOfPhase {
+			samples[i] = [2]float64{v, -v}
+		} else {
+			samples[i] = [2]float64{v, v}
+		}
+		s.Pos++
+	}
+	return n, n > 0
+}
+func (s *Sine) Err() error {
+	return nil
+}
+func (s *Sine) Len() int {
+	return s.Length
+}
+func (s *Sine) Position() int {
+	return s.Pos
+}
+func (s *Sine) Seek(p int) error {
+	return seek(&s.Pos, p, s.Live)
+}
+func (s *Sine) Close() error {
+	return nil
+}
+
+// available Get the number of samples left to stream, out of the requested ones
+func available(requested, length, pos int) int {
+	if length == 0 {
+		return requested
+	}
+	return max(0, min(requested, length-pos))
+}
+
+func seek(pos *int, p int, live bool) error {
+	if live {
+		return ErrNotSeekable
+	}
+	*pos = p
+	return nil
+}
+
+// Pull Get the next n samples of a streamer
+func Pull(s beep.Streamer, n int) [][2]float64 {
+	samples := make([][2]float64, n)
+	s.Stream(samples)
+	return samples
+}